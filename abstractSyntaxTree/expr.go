@@ -11,7 +11,7 @@ type BinaryExpr struct {
 	Right    Expr
 }
 
-func (e BinaryExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *BinaryExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitBinaryExpr(e)
 }
 
@@ -19,7 +19,7 @@ type GroupingExpr struct {
 	Expression Expr
 }
 
-func (e GroupingExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *GroupingExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitGroupingExpr(e)
 }
 
@@ -27,7 +27,7 @@ type LiteralExpr struct {
 	Value interface{}
 }
 
-func (e LiteralExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *LiteralExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitLiteralExpr(e)
 }
 
@@ -36,7 +36,7 @@ type UnaryExpr struct {
 	Right    Expr
 }
 
-func (e UnaryExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *UnaryExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitUnaryExpr(e)
 }
 
@@ -44,7 +44,7 @@ type VariableExpr struct {
 	Name t.Token
 }
 
-func (e VariableExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *VariableExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitVariableExpr(e)
 }
 
@@ -53,7 +53,7 @@ type AssignExpr struct {
 	Value Expr
 }
 
-func (e AssignExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *AssignExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitAssignExpr(e)
 }
 
@@ -63,7 +63,7 @@ type LogicalExpr struct {
 	Right    Expr
 }
 
-func (e LogicalExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *LogicalExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitLogicalExpr(e)
 }
 
@@ -71,9 +71,16 @@ type CallExpr struct {
 	Callee     Expr
 	Paren      t.Token
 	Arguements []Expr
+	//the name: value arguements, which come after the positional ones
+	NamedArguements []NamedArguement
 }
 
-func (e CallExpr) Accept(visitor ExprVisitor) interface{} {
+type NamedArguement struct {
+	Name  t.Token
+	Value Expr
+}
+
+func (e *CallExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitCallExpr(e)
 }
 
@@ -82,7 +89,7 @@ type GetExpr struct {
 	Name   t.Token
 }
 
-func (e GetExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *GetExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitGetExpr(e)
 }
 
@@ -92,7 +99,7 @@ type SetExpr struct {
 	Value  Expr
 }
 
-func (e SetExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *SetExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitSetExpr(e)
 }
 
@@ -100,7 +107,7 @@ type ThisExpr struct {
 	Keyword t.Token
 }
 
-func (e ThisExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *ThisExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitThisExpr(e)
 }
 
@@ -109,21 +116,78 @@ type SuperExpr struct {
 	Method  t.Token
 }
 
-func (e SuperExpr) Accept(visitor ExprVisitor) interface{} {
+func (e *SuperExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitSuperExpr(e)
 }
 
+// FunctionExpr is an anonymous function, written fun (a) { ... } or (a) => a. the name of its Declaration has an
+// empty lexeme and Keyword is the 'fun' or '=>' it was written with
+type FunctionExpr struct {
+	Keyword     t.Token
+	Declaration *FunctionStmt
+}
+
+func (e *FunctionExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitFunctionExpr(e)
+}
+
+type ListExpr struct {
+	Bracket  t.Token
+	Elements []Expr
+}
+
+func (e *ListExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitListExpr(e)
+}
+
+// a map literal. Keys[i] maps to Values[i]
+type MapExpr struct {
+	Brace  t.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+func (e *MapExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitMapExpr(e)
+}
+
+type IndexExpr struct {
+	Object  Expr
+	Bracket t.Token
+	Index   Expr
+}
+
+func (e *IndexExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitIndexExpr(e)
+}
+
+type SetIndexExpr struct {
+	Object  Expr
+	Bracket t.Token
+	Index   Expr
+	Value   Expr
+}
+
+func (e *SetIndexExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitSetIndexExpr(e)
+}
+
 type ExprVisitor interface {
-	VisitBinaryExpr(expr BinaryExpr) interface{}
-	VisitGroupingExpr(expr GroupingExpr) interface{}
-	VisitLiteralExpr(expr LiteralExpr) interface{}
-	VisitUnaryExpr(expr UnaryExpr) interface{}
-	VisitVariableExpr(expr VariableExpr) interface{}
-	VisitAssignExpr(expr AssignExpr) interface{}
-	VisitLogicalExpr(expr LogicalExpr) interface{}
-	VisitCallExpr(expr CallExpr) interface{}
-	VisitGetExpr(expr GetExpr) interface{}
-	VisitSetExpr(expr SetExpr) interface{}
-	VisitThisExpr(expr ThisExpr) interface{}
-	VisitSuperExpr(expr SuperExpr) interface{}
+	VisitBinaryExpr(expr *BinaryExpr) interface{}
+	VisitGroupingExpr(expr *GroupingExpr) interface{}
+	VisitLiteralExpr(expr *LiteralExpr) interface{}
+	VisitUnaryExpr(expr *UnaryExpr) interface{}
+	VisitVariableExpr(expr *VariableExpr) interface{}
+	VisitAssignExpr(expr *AssignExpr) interface{}
+	VisitLogicalExpr(expr *LogicalExpr) interface{}
+	VisitCallExpr(expr *CallExpr) interface{}
+	VisitGetExpr(expr *GetExpr) interface{}
+	VisitSetExpr(expr *SetExpr) interface{}
+	VisitThisExpr(expr *ThisExpr) interface{}
+	VisitSuperExpr(expr *SuperExpr) interface{}
+	VisitListExpr(expr *ListExpr) interface{}
+	VisitMapExpr(expr *MapExpr) interface{}
+	VisitIndexExpr(expr *IndexExpr) interface{}
+	VisitSetIndexExpr(expr *SetIndexExpr) interface{}
+	VisitFunctionExpr(expr *FunctionExpr) interface{}
 }
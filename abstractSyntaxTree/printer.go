@@ -1,9 +1,11 @@
 package abstractSyntaxTree
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Printer struct {
-	ExprVisitor
 }
 
 func NewPrinter() *Printer {
@@ -24,18 +26,82 @@ func (p *Printer) parenthesise(name string, exprs ...Expr) string {
 	return stringBuilder
 
 }
-func (p *Printer) VisitBinaryExpr(expr BinaryExpr) interface{} {
+func (p *Printer) VisitBinaryExpr(expr *BinaryExpr) interface{} {
 	return p.parenthesise(expr.Operator.Lexeme, expr.Left, expr.Right)
 }
-func (p *Printer) VisitGroupingExpr(expr GroupingExpr) interface{} {
+func (p *Printer) VisitGroupingExpr(expr *GroupingExpr) interface{} {
 	return p.parenthesise("group", expr.Expression)
 }
-func (p *Printer) VisitLiteralExpr(expr LiteralExpr) interface{} {
+func (p *Printer) VisitLiteralExpr(expr *LiteralExpr) interface{} {
 	if expr.Value == nil {
 		return "nil"
 	}
 	return fmt.Sprint(expr.Value)
 }
-func (p *Printer) VisitUnaryExpr(expr UnaryExpr) interface{} {
+func (p *Printer) VisitUnaryExpr(expr *UnaryExpr) interface{} {
 	return p.parenthesise(expr.Operator.Lexeme, expr.Right)
 }
+func (p *Printer) VisitVariableExpr(expr *VariableExpr) interface{} {
+	return expr.Name.Lexeme
+}
+func (p *Printer) VisitAssignExpr(expr *AssignExpr) interface{} {
+	return p.parenthesise(fmt.Sprintf("= %v", expr.Name.Lexeme), expr.Value)
+}
+func (p *Printer) VisitLogicalExpr(expr *LogicalExpr) interface{} {
+	return p.parenthesise(expr.Operator.Lexeme, expr.Left, expr.Right)
+}
+func (p *Printer) VisitCallExpr(expr *CallExpr) interface{} {
+	parts := []string{"call", fmt.Sprint(expr.Callee.Accept(p))}
+	for i := 0; i < len(expr.Arguements); i++ {
+		parts = append(parts, fmt.Sprint(expr.Arguements[i].Accept(p)))
+	}
+	//named arguements follow the positional ones, each as (name: value)
+	for i := 0; i < len(expr.NamedArguements); i++ {
+		named := expr.NamedArguements[i]
+		parts = append(parts, fmt.Sprintf("(%v: %v)", named.Name.Lexeme, named.Value.Accept(p)))
+	}
+	return fmt.Sprintf("(%v)", strings.Join(parts, " "))
+}
+func (p *Printer) VisitGetExpr(expr *GetExpr) interface{} {
+	return p.parenthesise(fmt.Sprintf(". %v", expr.Name.Lexeme), expr.Object)
+}
+func (p *Printer) VisitSetExpr(expr *SetExpr) interface{} {
+	return p.parenthesise(fmt.Sprintf("= . %v", expr.Name.Lexeme), expr.Object, expr.Value)
+}
+func (p *Printer) VisitThisExpr(expr *ThisExpr) interface{} {
+	return "this"
+}
+func (p *Printer) VisitSuperExpr(expr *SuperExpr) interface{} {
+	return fmt.Sprintf("(super %v)", expr.Method.Lexeme)
+}
+func (p *Printer) VisitListExpr(expr *ListExpr) interface{} {
+	return p.parenthesise("list", expr.Elements...)
+}
+func (p *Printer) VisitMapExpr(expr *MapExpr) interface{} {
+	var entries []Expr
+	for i := 0; i < len(expr.Keys); i++ {
+		entries = append(entries, expr.Keys[i], expr.Values[i])
+	}
+	return p.parenthesise("map", entries...)
+}
+func (p *Printer) VisitIndexExpr(expr *IndexExpr) interface{} {
+	return p.parenthesise("[]", expr.Object, expr.Index)
+}
+func (p *Printer) VisitSetIndexExpr(expr *SetIndexExpr) interface{} {
+	return p.parenthesise("= []", expr.Object, expr.Index, expr.Value)
+}
+func (p *Printer) VisitFunctionExpr(expr *FunctionExpr) interface{} {
+	declaration := expr.Declaration
+	var params []string
+	for i := 0; i < len(declaration.Params); i++ {
+		if declaration.Defaults[i] != nil {
+			params = append(params, fmt.Sprintf("(= %v %v)", declaration.Params[i].Lexeme, declaration.Defaults[i].Accept(p)))
+		} else {
+			params = append(params, declaration.Params[i].Lexeme)
+		}
+	}
+	if declaration.Rest != nil {
+		params = append(params, "..."+declaration.Rest.Lexeme)
+	}
+	return fmt.Sprintf("(fun (%v))", strings.Join(params, " "))
+}
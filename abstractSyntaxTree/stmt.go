@@ -11,7 +11,7 @@ type ExpressionStmt struct {
 	Expression Expr
 }
 
-func (s ExpressionStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *ExpressionStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitExpressionStmt(s)
 }
 
@@ -19,7 +19,7 @@ type PrintStmt struct {
 	Expression Expr
 }
 
-func (s PrintStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *PrintStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitPrintStmt(s)
 }
 
@@ -28,7 +28,7 @@ type VarStmt struct {
 	Name        t.Token
 }
 
-func (s VarStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *VarStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitVarStmt(s)
 }
 
@@ -36,7 +36,7 @@ type BlockStmt struct {
 	Statements []Stmt
 }
 
-func (s BlockStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *BlockStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitBlockStmt(s)
 }
 
@@ -46,26 +46,34 @@ type IfStmt struct {
 	ElseBranch Stmt
 }
 
-func (s IfStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *IfStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitIfStmt(s)
 }
 
 type WhileStmt struct {
 	Condition Expr
 	Body      Stmt
+	//the increment clause of a for loop, run after the body even when it continues. nil for while loops
+	Increment Expr
+	//the label written before the loop, nil when it has none
+	Label *t.Token
 }
 
-func (s WhileStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *WhileStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitWhileStmt(s)
 }
 
 type FunctionStmt struct {
 	Name   t.Token
 	Params []t.Token
-	Body   []Stmt
+	//the default value of each parameter, nil for parameters that have to be given
+	Defaults []Expr
+	//the parameter the arguements past Params are collected into, nil when there is none
+	Rest *t.Token
+	Body []Stmt
 }
 
-func (s FunctionStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *FunctionStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitFunctionStmt(s)
 }
 
@@ -74,28 +82,50 @@ type ReturnStmt struct {
 	Value   Expr
 }
 
-func (s ReturnStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *ReturnStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitReturnStmt(s)
 }
 
 type ClassStmt struct {
 	Name       t.Token
 	Superclass *VariableExpr
-	Methods    []FunctionStmt
+	Methods    []*FunctionStmt
 }
 
-func (s ClassStmt) Accept(visitor StmtVisitor) interface{} {
+func (s *ClassStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitClassStmt(s)
 }
 
+type BreakStmt struct {
+	Keyword t.Token
+	//the label of the loop to break out of, nil for the innermost loop
+	Label *t.Token
+}
+
+func (s *BreakStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitBreakStmt(s)
+}
+
+type ContinueStmt struct {
+	Keyword t.Token
+	//the label of the loop to continue, nil for the innermost loop
+	Label *t.Token
+}
+
+func (s *ContinueStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitContinueStmt(s)
+}
+
 type StmtVisitor interface {
-	VisitExpressionStmt(stmt ExpressionStmt) interface{}
-	VisitPrintStmt(stmt PrintStmt) interface{}
-	VisitVarStmt(stmt VarStmt) interface{}
-	VisitBlockStmt(stmt BlockStmt) interface{}
-	VisitIfStmt(stmt IfStmt) interface{}
-	VisitWhileStmt(stmt WhileStmt) interface{}
-	VisitFunctionStmt(stmt FunctionStmt) interface{}
-	VisitReturnStmt(stmt ReturnStmt) interface{}
-	VisitClassStmt(stmt ClassStmt) interface{}
+	VisitExpressionStmt(stmt *ExpressionStmt) interface{}
+	VisitPrintStmt(stmt *PrintStmt) interface{}
+	VisitVarStmt(stmt *VarStmt) interface{}
+	VisitBlockStmt(stmt *BlockStmt) interface{}
+	VisitIfStmt(stmt *IfStmt) interface{}
+	VisitWhileStmt(stmt *WhileStmt) interface{}
+	VisitFunctionStmt(stmt *FunctionStmt) interface{}
+	VisitReturnStmt(stmt *ReturnStmt) interface{}
+	VisitClassStmt(stmt *ClassStmt) interface{}
+	VisitBreakStmt(stmt *BreakStmt) interface{}
+	VisitContinueStmt(stmt *ContinueStmt) interface{}
 }
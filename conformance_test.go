@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	"github.com/constwhite/golox-interpreter/repl"
+)
+
+// the conformance suite runs every .lox program under testdata and checks what it prints and reports against the
+// comments in the program, in the style of the Crafting Interpreters test corpus:
+//
+//	print 1 + 2; // expect: 3
+//	print nil + 1; // expect runtime error: operands must be two numbers or two strings
+//	var = 1; // Error at '=': expect variable name
+//	// [line 4] Error at end: expect '}' after block
+//	"unterminated // Error: Unterminated string
+//
+// expectations are matched against the line of the comment unless it gives a [line N] of its own
+var (
+	expectOutputPattern       = regexp.MustCompile(`// expect: ?(.*)`)
+	expectRuntimeErrorPattern = regexp.MustCompile(`// expect runtime error: (.+)`)
+	expectErrorPattern        = regexp.MustCompile(`// (\[line (\d+)\] )?(Error.*)`)
+)
+
+type expectations struct {
+	output       []string
+	errors       []string
+	runtimeError string
+	runtimeLine  int
+}
+
+func parseExpectations(source string) expectations {
+	var expected expectations
+	lines := strings.Split(source, "\n")
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+		lineNumber := index + 1
+		if match := expectOutputPattern.FindStringSubmatch(line); match != nil {
+			expected.output = append(expected.output, match[1])
+		} else if match := expectRuntimeErrorPattern.FindStringSubmatch(line); match != nil {
+			expected.runtimeError = match[1]
+			expected.runtimeLine = lineNumber
+		} else if match := expectErrorPattern.FindStringSubmatch(line); match != nil {
+			if match[2] != "" {
+				lineNumber, _ = strconv.Atoi(match[2])
+			}
+			expected.errors = append(expected.errors, fmt.Sprintf("[line %v] %v", lineNumber, match[3]))
+		}
+	}
+	return expected
+}
+
+// keeps the diagnostics a session reports so they can be compared with the expectations
+type collector struct {
+	diagnostics []e.Diagnostic
+}
+
+func (c *collector) Render(diagnostics []e.Diagnostic) {
+	c.diagnostics = append(c.diagnostics, diagnostics...)
+}
+
+// formats a compile error the way the test comments write them
+func formatError(diagnostic e.Diagnostic) string {
+	label := "Error"
+	if where := diagnostic.Where(); where != "" {
+		label = fmt.Sprintf("%v %v", label, where)
+	}
+	return fmt.Sprintf("[line %v] %v: %v", diagnostic.Span.Start.Line, label, diagnostic.Message)
+}
+
+func TestConformance(t *testing.T) {
+	var paths []string
+	err := filepath.WalkDir("testdata", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".lox" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no .lox programs found under testdata")
+	}
+
+	for index := 0; index < len(paths); index++ {
+		path := paths[index]
+		name := strings.TrimSuffix(filepath.ToSlash(strings.TrimPrefix(path, "testdata"+string(filepath.Separator))), ".lox")
+		t.Run(name, func(t *testing.T) {
+			runConformance(t, path)
+		})
+	}
+}
+
+func runConformance(t *testing.T, path string) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := string(file)
+	expected := parseExpectations(source)
+
+	var stdOut, stdErr bytes.Buffer
+	diagnostics := &collector{}
+	session := repl.NewSession(&stdOut, &stdErr)
+	session.Renderer = diagnostics
+	hadError, hadRuntimeError, _ := session.Run(source)
+
+	output := strings.Split(strings.TrimSuffix(stdOut.String(), "\n"), "\n")
+	if stdOut.Len() == 0 {
+		output = nil
+	}
+	if strings.Join(output, "\n") != strings.Join(expected.output, "\n") {
+		t.Errorf("output mismatch\n got:\n%v\nwant:\n%v", strings.Join(output, "\n"), strings.Join(expected.output, "\n"))
+	}
+
+	var errors []string
+	var runtimeErrors []e.Diagnostic
+	for index := 0; index < len(diagnostics.diagnostics); index++ {
+		diagnostic := diagnostics.diagnostics[index]
+		if diagnostic.Phase == e.PhaseRuntime {
+			runtimeErrors = append(runtimeErrors, diagnostic)
+		} else {
+			errors = append(errors, formatError(diagnostic))
+		}
+	}
+	if strings.Join(errors, "\n") != strings.Join(expected.errors, "\n") {
+		t.Errorf("compile errors mismatch\n got:\n%v\nwant:\n%v", strings.Join(errors, "\n"), strings.Join(expected.errors, "\n"))
+	}
+	if hadError != (len(expected.errors) > 0) {
+		t.Errorf("hadError = %v, want %v", hadError, len(expected.errors) > 0)
+	}
+
+	if expected.runtimeError == "" {
+		if len(runtimeErrors) > 0 {
+			t.Errorf("unexpected runtime error: [line %v] %v", runtimeErrors[0].Span.Start.Line, runtimeErrors[0].Message)
+		}
+		return
+	}
+	if !hadRuntimeError || len(runtimeErrors) != 1 {
+		t.Errorf("expected runtime error %q on line %v, got %v runtime errors", expected.runtimeError, expected.runtimeLine, len(runtimeErrors))
+		return
+	}
+	got := runtimeErrors[0]
+	if got.Message != expected.runtimeError || got.Span.Start.Line != expected.runtimeLine {
+		t.Errorf("runtime error mismatch\n got: [line %v] %v\nwant: [line %v] %v", got.Span.Start.Line, got.Message, expected.runtimeLine, expected.runtimeError)
+	}
+}
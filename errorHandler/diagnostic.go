@@ -0,0 +1,157 @@
+package errorHandler
+
+import (
+	"fmt"
+	"strings"
+
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+type Severity uint8
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", uint8(s))
+}
+
+// the stage of running a program that found the problem
+type Phase uint8
+
+const (
+	PhaseScan Phase = iota
+	PhaseParse
+	PhaseResolve
+	PhaseRuntime
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseScan:
+		return "scan"
+	case PhaseParse:
+		return "parse"
+	case PhaseResolve:
+		return "resolve"
+	case PhaseRuntime:
+		return "runtime"
+	}
+	return fmt.Sprintf("Phase(%d)", uint8(p))
+}
+
+// codes identify the kind of problem independently of the wording of the message
+const (
+	//scanner
+	CodeUnexpectedCharacter = "unexpected-character"
+	CodeUnterminatedString  = "unterminated-string"
+
+	//parser
+	CodeSyntax                  = "syntax"
+	CodeInvalidAssignmentTarget = "invalid-assignment-target"
+	CodeTooManyParameters       = "too-many-parameters"
+	CodeTooManyArguements       = "too-many-arguements"
+
+	//resolver
+	CodeTopLevelReturn         = "top-level-return"
+	CodeInitialiserReturn      = "initialiser-return"
+	CodeSelfInheritance        = "self-inheritance"
+	CodeSuperOutsideClass      = "super-outside-class"
+	CodeSuperWithoutSuperclass = "super-without-superclass"
+	CodeThisOutsideClass       = "this-outside-class"
+	CodeOwnInitialiser         = "own-initialiser"
+	CodeBreakOutsideLoop       = "break-outside-loop"
+	CodeContinueOutsideLoop    = "continue-outside-loop"
+	CodeUndefinedLabel         = "undefined-label"
+	CodeDuplicateLabel         = "duplicate-label"
+
+	//interpreter
+	CodeOperandType        = "operand-type"
+	CodeUndefinedVariable  = "undefined-variable"
+	CodeUndefinedProperty  = "undefined-property"
+	CodeNotCallable        = "not-callable"
+	CodeArity              = "arity"
+	CodeNotInstance        = "not-instance"
+	CodeSuperclassNotClass = "superclass-not-class"
+	CodeNative             = "native"
+	CodeStatementLimit     = "statement-limit"
+	CodeStackOverflow      = "stack-overflow"
+	CodeTimeout            = "timeout"
+	CodeCancelled          = "cancelled"
+	CodeQuota              = "quota"
+	CodeNotIndexable       = "not-indexable"
+	CodeIndexType          = "index-type"
+	CodeIndexOutOfRange    = "index-out-of-range"
+	CodeUndefinedKey       = "undefined-key"
+	CodeInvalidKey         = "invalid-key"
+	CodeNamedArguement     = "named-arguement"
+)
+
+// extra information attached to a diagnostic. Span is left empty when the note is not about a place in the source
+type Note struct {
+	Message string
+	Span    t.Span
+}
+
+// a problem found in a program by the scanner, parser, resolver or interpreter
+type Diagnostic struct {
+	Severity Severity
+	Phase    Phase
+	Code     string
+	Message  string
+	Span     t.Span
+	Notes    []Note
+}
+
+func NewError(phase Phase, code string, message string, span t.Span) Diagnostic {
+	return Diagnostic{Severity: SeverityError, Phase: phase, Code: code, Message: message, Span: span}
+}
+
+// describes where a parse or resolve error was found, "at 'lexeme'" or "at end" for the empty span of the EOF
+// token. scan and runtime errors are not reported against a token so return ""
+func (d Diagnostic) Where() string {
+	if d.Phase != PhaseParse && d.Phase != PhaseResolve {
+		return ""
+	}
+	if d.Span.Start.Offset == d.Span.End.Offset || d.Span.Source == nil {
+		return "at end"
+	}
+	return fmt.Sprintf("at '%v'", (*d.Span.Source)[d.Span.Start.Offset:d.Span.End.Offset])
+}
+
+// the one line summary of the diagnostic, "[line 3:5] Error at 'x': message"
+func (d Diagnostic) String() string {
+	label := "Error"
+	if d.Severity == SeverityWarning {
+		label = "Warning"
+	}
+	if d.Phase == PhaseRuntime {
+		label = fmt.Sprintf("Runtime %v", strings.ToLower(label))
+	}
+	if where := d.Where(); where != "" {
+		label = fmt.Sprintf("%v %v", label, where)
+	}
+	//problems raised by calls from Go have no place in the source
+	if d.Span.Start.Line == 0 {
+		return fmt.Sprintf("%v: %v", label, d.Message)
+	}
+	return fmt.Sprintf("[line %v:%v] %v: %v", d.Span.Start.Line, d.Span.Start.Column, label, d.Message)
+}
+
+// reports whether any of the diagnostics is an error rather than a warning
+func HasErrors(diagnostics []Diagnostic) bool {
+	for index := 0; index < len(diagnostics); index++ {
+		if diagnostics[index].Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -1,18 +1,147 @@
 package errorHandler
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"unicode/utf8"
+
+	t "github.com/constwhite/golox-interpreter/token"
 )
 
-func ReportError(w io.Writer, msg string, where string, line int) {
-	fmt.Fprintf(w, "[line: %v] Error: %s %s\n", line, msg, where)
+// writes diagnostics out in a particular format
+type Renderer interface {
+	Render(diagnostics []Diagnostic)
+}
+
+// TextRenderer writes diagnostics for people to read, showing the source line under each one
+type TextRenderer struct {
+	w io.Writer
+}
+
+func NewTextRenderer(w io.Writer) *TextRenderer {
+	return &TextRenderer{w: w}
+}
+
+func (r *TextRenderer) Render(diagnostics []Diagnostic) {
+	for index := 0; index < len(diagnostics); index++ {
+		diagnostic := diagnostics[index]
+		fmt.Fprintln(r.w, diagnostic)
+		RenderSpan(r.w, diagnostic.Span)
+		for noteIndex := 0; noteIndex < len(diagnostic.Notes); noteIndex++ {
+			note := diagnostic.Notes[noteIndex]
+			fmt.Fprintf(r.w, "  note: %v\n", note.Message)
+			RenderSpan(r.w, note.Span)
+		}
+	}
+}
+
+// JSONRenderer writes each diagnostic as a JSON object on its own line for editors and CI to consume
+type JSONRenderer struct {
+	w io.Writer
+}
+
+func NewJSONRenderer(w io.Writer) *JSONRenderer {
+	return &JSONRenderer{w: w}
+}
+
+type jsonPosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonSpan struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonNote struct {
+	Message string    `json:"message"`
+	Span    *jsonSpan `json:"span,omitempty"`
+}
+
+type jsonDiagnostic struct {
+	Severity string     `json:"severity"`
+	Phase    string     `json:"phase"`
+	Code     string     `json:"code"`
+	Message  string     `json:"message"`
+	Span     *jsonSpan  `json:"span,omitempty"`
+	Notes    []jsonNote `json:"notes,omitempty"`
+}
 
+func (r *JSONRenderer) Render(diagnostics []Diagnostic) {
+	encoder := json.NewEncoder(r.w)
+	for index := 0; index < len(diagnostics); index++ {
+		diagnostic := diagnostics[index]
+		out := jsonDiagnostic{
+			Severity: diagnostic.Severity.String(),
+			Phase:    diagnostic.Phase.String(),
+			Code:     diagnostic.Code,
+			Message:  diagnostic.Message,
+			Span:     toJSONSpan(diagnostic.Span),
+		}
+		for noteIndex := 0; noteIndex < len(diagnostic.Notes); noteIndex++ {
+			note := diagnostic.Notes[noteIndex]
+			out.Notes = append(out.Notes, jsonNote{Message: note.Message, Span: toJSONSpan(note.Span)})
+		}
+		encoder.Encode(out)
+	}
 }
-func RuntimeError(w io.Writer, err error, line int) {
-	fmt.Fprintf(w, "%v\n[line:%v]", err, line)
+
+// spans that were never set, such as on a note with no location, are left out
+func toJSONSpan(span t.Span) *jsonSpan {
+	if span == (t.Span{}) {
+		return nil
+	}
+	return &jsonSpan{
+		Start: jsonPosition(span.Start),
+		End:   jsonPosition(span.End),
+	}
 }
 
-func CompileError(w io.Writer, err error, line int) {
-	fmt.Fprintf(w, "%v\n[line:%v]", err, line)
+// prints the source line the span starts on with a caret under each character the span covers. a span running
+// onto later lines is underlined to the end of its first line, an empty span gets a single caret
+func RenderSpan(w io.Writer, span t.Span) {
+	if span.Source == nil {
+		return
+	}
+	source := *span.Source
+	if span.Start.Offset > len(source) || span.Start.Column < 1 {
+		return
+	}
+	lineStart := strings.LastIndexByte(source[:span.Start.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(source[lineStart:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(source)
+	} else {
+		lineEnd += lineStart
+	}
+	line := strings.TrimRight(source[lineStart:lineEnd], "\r")
+
+	width := 1
+	if span.End.Line == span.Start.Line && span.End.Column > span.Start.Column {
+		width = span.End.Column - span.Start.Column
+	} else if span.End.Line > span.Start.Line {
+		width = utf8.RuneCountInString(line) - span.Start.Column + 1
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	//tabs are kept in the padding so the carets line up with the line above
+	var padding strings.Builder
+	runes := []rune(line)
+	for index := 0; index < span.Start.Column-1; index++ {
+		if index < len(runes) && runes[index] == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	gutter := fmt.Sprint(span.Start.Line)
+	fmt.Fprintf(w, " %v | %v\n", gutter, line)
+	fmt.Fprintf(w, " %v | %v%v\n", strings.Repeat(" ", len(gutter)), padding.String(), strings.Repeat("^", width))
 }
@@ -0,0 +1,281 @@
+// Package golox embeds the Lox interpreter in Go programs. An Interpreter keeps its globals between calls so a host
+// can load a script once then call into it, read its variables or hand it values of its own.
+//
+// Go values passed in through Call and SetGlobal are converted to Lox values: numbers of any type become float64,
+// functions become natives, structs become instances and slices and maps are copied into lists and maps. Results come back as Lox values, which FromValue converts to Go types.
+package golox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	"github.com/constwhite/golox-interpreter/interpreter"
+	"github.com/constwhite/golox-interpreter/parser"
+	"github.com/constwhite/golox-interpreter/resolver"
+	"github.com/constwhite/golox-interpreter/scanner"
+)
+
+// NativeFunction is a Go function that Lox code can call, see interpreter.NativeFunction
+type NativeFunction = interpreter.NativeFunction
+
+// Value is a Lox value as Go sees it
+type Value = interpreter.Value
+
+// Exit is the error returned when a script calls exit, see interpreter.Exit. the host decides whether to end the
+// process with its Code
+type Exit = interpreter.Exit
+
+// Capabilities is a set of the kinds of access natives can need, see interpreter.Capabilities
+type Capabilities = interpreter.Capabilities
+
+const (
+	CapabilityFilesystem  = interpreter.CapabilityFilesystem
+	CapabilityEnvironment = interpreter.CapabilityEnvironment
+	CapabilityClock       = interpreter.CapabilityClock
+	CapabilityProcess     = interpreter.CapabilityProcess
+	AllCapabilities       = interpreter.AllCapabilities
+)
+
+type Options struct {
+	//where print writes to, os.Stdout when nil
+	Stdout io.Writer
+	//what the natives may reach outside the script. the zero value allows nothing, so natives such as clock and
+	//readFile are not defined
+	Capabilities Capabilities
+	//where natives get the time and random numbers from, the real time and a randomly seeded source when nil. set
+	//both so the same script always prints the same thing
+	Clock      func() time.Time
+	RandSource rand.Source
+	//limits on each Eval and Call, so scripts that loop forever or recurse without end are stopped. zero means no
+	//limit, except for MaxCallDepth where it means interpreter.DefaultMaxCallDepth
+	MaxStatements int
+	MaxCallDepth  int
+	Timeout       time.Duration
+	//quotas on what scripts create over the life of the Interpreter, zero means no quota. once one is used up
+	//every script that creates more stops with a runtime error
+	InstanceQuota    int
+	ClosureQuota     int
+	EnvironmentQuota int
+	StringByteQuota  int
+	ElementQuota     int
+}
+
+// Interpreter runs Lox source against one set of globals. it is not safe for use by more than one goroutine at a
+// time
+type Interpreter struct {
+	interpreter *interpreter.Interpreter
+	resolver    *resolver.Resolver
+}
+
+// CompileError is returned when source fails to scan, parse or resolve. none of the source has been run
+type CompileError struct {
+	Diagnostics []e.Diagnostic
+}
+
+func (err *CompileError) Error() string {
+	lines := make([]string, len(err.Diagnostics))
+	for index := 0; index < len(err.Diagnostics); index++ {
+		lines[index] = err.Diagnostics[index].String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RuntimeError is returned when a program stops on a runtime error. Trace is the Lox call stack at the point of
+// the error, innermost first
+type RuntimeError struct {
+	Diagnostic e.Diagnostic
+	Trace      []interpreter.Frame
+}
+
+func (err *RuntimeError) Error() string {
+	return err.Diagnostic.String()
+}
+
+func New(opts Options) *Interpreter {
+	stdOut := opts.Stdout
+	if stdOut == nil {
+		stdOut = os.Stdout
+	}
+	in := interpreter.NewInterpreter(stdOut, opts.Capabilities)
+	if opts.Clock != nil {
+		in.Clock = opts.Clock
+	}
+	if opts.RandSource != nil {
+		in.Random = rand.New(opts.RandSource)
+	}
+	in.Limits = interpreter.Limits{
+		MaxStatements:    opts.MaxStatements,
+		MaxCallDepth:     opts.MaxCallDepth,
+		Timeout:          opts.Timeout,
+		InstanceQuota:    opts.InstanceQuota,
+		ClosureQuota:     opts.ClosureQuota,
+		EnvironmentQuota: opts.EnvironmentQuota,
+		StringByteQuota:  opts.StringByteQuota,
+		ElementQuota:     opts.ElementQuota,
+	}
+	return &Interpreter{interpreter: in, resolver: resolver.NewResolver(in)}
+}
+
+// Eval runs Lox source. the globals it declares stay defined for later calls
+func (l *Interpreter) Eval(src string) error {
+	return l.EvalContext(context.Background(), src)
+}
+
+// EvalContext runs Lox source like Eval, stopping it with a runtime error if ctx is cancelled or its deadline
+// passes
+func (l *Interpreter) EvalContext(ctx context.Context, src string) error {
+	tokens, diagnostics := scanner.NewScanner(src).ScanTokens()
+	statements, parseDiagnostics := parser.NewParser(tokens).Parse()
+	diagnostics = append(diagnostics, parseDiagnostics...)
+	if e.HasErrors(diagnostics) {
+		return &CompileError{Diagnostics: diagnostics}
+	}
+	if diagnostics := l.resolver.Resolve(statements); e.HasErrors(diagnostics) {
+		return &CompileError{Diagnostics: diagnostics}
+	}
+	return l.runtimeError(l.interpreter.InterpretContext(ctx, statements))
+}
+
+// RunFile reads a Lox file and runs it with Eval
+func (l *Interpreter) RunFile(path string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return l.Eval(string(file))
+}
+
+// Call calls the global function or class fnName with the arguments, converted to Lox values, and returns the result
+func (l *Interpreter) Call(fnName string, args ...interface{}) (Value, error) {
+	return l.CallContext(context.Background(), fnName, args...)
+}
+
+// CallContext calls like Call, stopping the call with a runtime error if ctx is cancelled or its deadline passes
+func (l *Interpreter) CallContext(ctx context.Context, fnName string, args ...interface{}) (Value, error) {
+	callee, ok := l.GetGlobal(fnName)
+	if !ok {
+		return nil, fmt.Errorf("golox: undefined global '%v'", fnName)
+	}
+	return l.call(ctx, fnName, callee, args)
+}
+
+func (l *Interpreter) call(ctx context.Context, name string, callee Value, args []interface{}) (Value, error) {
+	arguements := make([]Value, len(args))
+	for index := 0; index < len(args); index++ {
+		value, err := l.interpreter.ToValue(args[index])
+		if err != nil {
+			return nil, fmt.Errorf("golox: arguement %v: %v", index+1, err)
+		}
+		arguements[index] = value
+	}
+	result, diagnostics, exit := l.interpreter.CallContext(ctx, name, callee, arguements)
+	if err := l.runtimeError(diagnostics, exit); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Callable is a Lox function, class or bound method that Go code can call after the script that made it has
+// finished, such as a handler the script registered
+type Callable struct {
+	lox   *Interpreter
+	name  string
+	value Value
+}
+
+// Callable returns a handle on the global function or class name
+func (l *Interpreter) Callable(name string) (*Callable, error) {
+	value, ok := l.GetGlobal(name)
+	if !ok {
+		return nil, fmt.Errorf("golox: undefined global '%v'", name)
+	}
+	callable, ok := l.AsCallable(value)
+	if !ok {
+		return nil, fmt.Errorf("golox: global '%v' is not a function or class", name)
+	}
+	callable.name = name
+	return callable, nil
+}
+
+// AsCallable returns a handle on a Lox value, such as a closure or bound method returned by a call, if it can be
+// called
+func (l *Interpreter) AsCallable(value Value) (*Callable, bool) {
+	if !interpreter.IsCallable(value) {
+		return nil, false
+	}
+	return &Callable{lox: l, name: l.interpreter.Stringify(value), value: value}, true
+}
+
+// Call calls the Lox value with the arguments, converted to Lox values. a ctx that is already done is returned as
+// the error without calling, one that is cancelled during the call stops it with a runtime error
+func (c *Callable) Call(ctx context.Context, args ...interface{}) (Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.lox.call(ctx, c.name, c.value, args)
+}
+
+// Value returns the Lox value the handle calls, so it can be passed back into Lox
+func (c *Callable) Value() Value {
+	return c.value
+}
+
+func (c *Callable) String() string {
+	return c.lox.interpreter.Stringify(c.value)
+}
+
+// GetGlobal returns the value of a global variable and whether it is defined
+func (l *Interpreter) GetGlobal(name string) (Value, bool) {
+	value, ok := l.interpreter.Globals.Values[name]
+	return value, ok
+}
+
+// SetGlobal converts a Go value to a Lox value and defines it as a global variable, replacing any value it already
+// has. a Go function is registered as a native named name
+func (l *Interpreter) SetGlobal(name string, value interface{}) error {
+	var converted Value
+	var err error
+	if reflect.ValueOf(value).Kind() == reflect.Func {
+		converted, err = l.interpreter.WrapFunc(name, value)
+	} else {
+		converted, err = l.interpreter.ToValue(value)
+	}
+	if err != nil {
+		return fmt.Errorf("golox: %v", err)
+	}
+	l.interpreter.Globals.Define(name, converted)
+	return nil
+}
+
+// FromValue converts a Lox value into the Go value that out points to
+func (l *Interpreter) FromValue(value Value, out interface{}) error {
+	return l.interpreter.FromValue(value, out)
+}
+
+// Allocations returns how many instances, closures, environments and string bytes scripts have created so far
+func (l *Interpreter) Allocations() interpreter.Allocations {
+	return l.interpreter.Allocations()
+}
+
+// DefineNative makes a Go function callable from Lox as a global
+func (l *Interpreter) DefineNative(native *NativeFunction) {
+	l.interpreter.DefineNative(native)
+}
+
+// returns the error a run ended with, a *RuntimeError or an *Exit, or nil if it finished
+func (l *Interpreter) runtimeError(diagnostics []e.Diagnostic, exit *Exit) error {
+	if exit != nil {
+		return exit
+	}
+	if !e.HasErrors(diagnostics) {
+		return nil
+	}
+	return &RuntimeError{Diagnostic: diagnostics[0], Trace: l.interpreter.LastErrorTrace()}
+}
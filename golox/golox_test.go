@@ -0,0 +1,636 @@
+package golox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	"github.com/constwhite/golox-interpreter/interpreter"
+)
+
+func TestEvalKeepsGlobals(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut})
+	if err := lox.Eval(`var greeting = "hi";`); err != nil {
+		t.Fatal(err)
+	}
+	if err := lox.Eval(`print greeting;`); err != nil {
+		t.Fatal(err)
+	}
+	if stdOut.String() != "hi\n" {
+		t.Errorf("printed %q, want %q", stdOut.String(), "hi\n")
+	}
+}
+
+func TestCall(t *testing.T) {
+	lox := New(Options{})
+	if err := lox.Eval(`fun add(a, b) { return a + b; }`); err != nil {
+		t.Fatal(err)
+	}
+	result, err := lox.Call("add", 1.0, 2.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 3.0 {
+		t.Errorf("add(1, 2) = %v, want 3", result)
+	}
+
+	var runtimeErr *RuntimeError
+	if _, err := lox.Call("add", 1.0); !errors.As(err, &runtimeErr) {
+		t.Errorf("add(1) returned %v, want a runtime error", err)
+	}
+	if _, err := lox.Call("missing"); err == nil {
+		t.Error("calling an undefined global did not fail")
+	}
+
+	if err := lox.Eval(`fun total(a, b = 10, ...rest) {
+		for (var i = 0; i < rest.len(); i = i + 1) a = a + rest[i];
+		return a + b;
+	}`); err != nil {
+		t.Fatal(err)
+	}
+	result, err = lox.Call("total", 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 11.0 {
+		t.Errorf("total(1) = %v, want 11", result)
+	}
+	result, err = lox.Call("total", 1.0, 2.0, 3.0, 4.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 10.0 {
+		t.Errorf("total(1, 2, 3, 4) = %v, want 10", result)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	lox := New(Options{})
+	var compileErr *CompileError
+	if err := lox.Eval(`var = 1;`); !errors.As(err, &compileErr) {
+		t.Errorf("got %v, want a compile error", err)
+	}
+
+	//the resolver is kept between inputs, so an error in one must not leak into the next
+	if err := lox.Eval(`class C < C {}`); !errors.As(err, &compileErr) {
+		t.Errorf("got %v, want a compile error", err)
+	}
+	err := lox.Eval(`print this;`)
+	if !errors.As(err, &compileErr) || compileErr.Diagnostics[0].Code != e.CodeThisOutsideClass {
+		t.Errorf("got %v, want the 'this' outside of a class error", err)
+	}
+
+	var runtimeErr *RuntimeError
+	err = lox.Eval("fun f() { return nil + 1; }\nf();")
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("got %v, want a runtime error", err)
+	}
+	if len(runtimeErr.Trace) != 1 || runtimeErr.Trace[0].Function != "f" {
+		t.Errorf("trace = %v, want [f()]", runtimeErr.Trace)
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut})
+	lox.SetGlobal("answer", 42.0)
+	if err := lox.Eval(`var doubled = answer * 2; print doubled;`); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := lox.GetGlobal("doubled"); !ok || value != 84.0 {
+		t.Errorf("doubled = %v, %v, want 84, true", value, ok)
+	}
+	if _, ok := lox.GetGlobal("missing"); ok {
+		t.Error("GetGlobal reported an undefined global as defined")
+	}
+}
+
+func TestDefineNative(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut})
+	lox.DefineNative(&NativeFunction{
+		Name:     "sum",
+		Variadic: true,
+		Function: func(in *interpreter.Interpreter, arguements []Value) (Value, error) {
+			total := 0.0
+			for index := 0; index < len(arguements); index++ {
+				number, ok := arguements[index].(float64)
+				if !ok {
+					return nil, errors.New("sum takes numbers")
+				}
+				total += number
+			}
+			return total, nil
+		},
+	})
+	if err := lox.Eval(`print sum(); print sum(1, 2, 3); print sum;`); err != nil {
+		t.Fatal(err)
+	}
+	if stdOut.String() != "0\n6\n<native fn>\n" {
+		t.Errorf("printed %q", stdOut.String())
+	}
+
+	var runtimeErr *RuntimeError
+	if err := lox.Eval(`sum(1, "2");`); !errors.As(err, &runtimeErr) {
+		t.Fatalf("got %v, want a runtime error", err)
+	}
+	if runtimeErr.Diagnostic.Message != "sum takes numbers" || runtimeErr.Diagnostic.Span.Start.Line != 1 {
+		t.Errorf("got %v", runtimeErr)
+	}
+	if len(runtimeErr.Trace) != 1 || runtimeErr.Trace[0].Function != "sum" {
+		t.Errorf("trace = %v, want [sum()]", runtimeErr.Trace)
+	}
+
+	//Go values a native returns are converted into Lox values
+	stdOut.Reset()
+	lox.DefineNative(&NativeFunction{Name: "count", Function: func(in *interpreter.Interpreter, arguements []Value) (Value, error) {
+		return 3, nil
+	}})
+	lox.DefineNative(&NativeFunction{Name: "digits", Function: func(in *interpreter.Interpreter, arguements []Value) (Value, error) {
+		return []int{1, 2}, nil
+	}})
+	if err := lox.Eval(`print count() + 1; print digits(); print digits() == digits(); var m = {}; m[digits()] = 1;`); err != nil {
+		t.Fatal(err)
+	}
+	if stdOut.String() != "4\n[1, 2]\nfalse\n" {
+		t.Errorf("printed %q", stdOut.String())
+	}
+
+	lox.DefineNative(&NativeFunction{Name: "channel", Function: func(in *interpreter.Interpreter, arguements []Value) (Value, error) {
+		return make(chan int), nil
+	}})
+	if err := lox.Eval(`channel();`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Code != e.CodeNative {
+		t.Errorf("got %v, want a native runtime error", err)
+	}
+}
+
+type point struct {
+	X, Y   int
+	hidden string
+}
+
+func (p *point) Move(dx, dy int) {
+	p.X += dx
+	p.Y += dy
+}
+
+func (p point) Sum() int {
+	return p.X + p.Y
+}
+
+func TestGoValues(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut})
+	err := lox.SetGlobal("repeat", func(s string, n int) (string, error) {
+		if n < 0 {
+			return "", errors.New("negative count")
+		}
+		return strings.Repeat(s, n), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &point{X: 1, Y: 2}
+	if err := lox.SetGlobal("p", p); err != nil {
+		t.Fatal(err)
+	}
+	if err := lox.SetGlobal("xs", []int{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lox.SetGlobal("ages", map[string]uint8{"ann": 30}); err != nil {
+		t.Fatal(err)
+	}
+	err = lox.Eval(`
+print repeat("ab", 2);
+print p;
+p.Move(1, 1);
+print p.X;
+p.Y = 10;
+print p.Sum();
+print xs;
+xs[0] = 7;
+print xs[0];
+print ages["ann"];
+print ages.has("bob");
+print ages;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "abab\npoint instance\n2\n12\n[1, 2, 3]\n7\n30\nfalse\n{ann: 30}\n"
+	if stdOut.String() != want {
+		t.Errorf("printed %q, want %q", stdOut.String(), want)
+	}
+	if p.X != 2 || p.Y != 10 {
+		t.Errorf("p = %+v, want {X:2 Y:10}", *p)
+	}
+
+	var runtimeErr *RuntimeError
+	if err := lox.Eval(`repeat("a", 1.5);`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "arguement 2: cannot use 1.5 as int" {
+		t.Errorf("got %v", err)
+	}
+	if err := lox.Eval(`repeat("a", -1);`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "negative count" {
+		t.Errorf("got %v", err)
+	}
+	if err := lox.Eval(`xs[3];`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "list index 3 out of range for length 3" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	lox := New(Options{})
+	err := lox.Eval(`
+class Point {}
+var p = Point();
+p.X = 3;
+p.Y = 4;
+fun twice(f, x) { return f(f(x)); }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := lox.GetGlobal("p")
+	var p point
+	if err := lox.FromValue(value, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.X != 3 || p.Y != 4 {
+		t.Errorf("p = %+v, want {X:3 Y:4}", p)
+	}
+
+	twice, _ := lox.GetGlobal("twice")
+	var goTwice func(func(int) int, int) (int, error)
+	if err := lox.FromValue(twice, &goTwice); err != nil {
+		t.Fatal(err)
+	}
+	result, err := goTwice(func(x int) int { return x * 3 }, 2)
+	if err != nil || result != 18 {
+		t.Errorf("twice(triple, 2) = %v, %v, want 18", result, err)
+	}
+
+	var xs []float64
+	if err := lox.FromValue("not a list", &xs); err == nil {
+		t.Error("converting a string to a slice did not fail")
+	}
+	if err := lox.Eval(`var names = ["a", "b"];`); err != nil {
+		t.Fatal(err)
+	}
+	names, _ := lox.GetGlobal("names")
+	var goNames []string
+	if err := lox.FromValue(names, &goNames); err != nil || strings.Join(goNames, ",") != "a,b" {
+		t.Errorf("names = %v, %v, want [a b]", goNames, err)
+	}
+
+	if err := lox.Eval(`var counts = {"a": 1, "b": 2};`); err != nil {
+		t.Fatal(err)
+	}
+	counts, _ := lox.GetGlobal("counts")
+	var goCounts map[string]int
+	if err := lox.FromValue(counts, &goCounts); err != nil || len(goCounts) != 2 || goCounts["b"] != 2 {
+		t.Errorf("counts = %v, %v, want map[a:1 b:2]", goCounts, err)
+	}
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func (n *node) Rename(name string) {
+	n.Name = name
+}
+
+func TestCyclicValues(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut})
+	a := &node{Name: "a"}
+	b := &node{Name: "b", Next: a}
+	a.Next = b
+	if err := lox.SetGlobal("a", a); err != nil {
+		t.Fatal(err)
+	}
+	items := []interface{}{1.0, nil}
+	items[1] = items
+	if err := lox.SetGlobal("items", items); err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]interface{}{}
+	byName["self"] = byName
+	if err := lox.SetGlobal("byName", byName); err != nil {
+		t.Fatal(err)
+	}
+	err := lox.Eval(`
+print a.Next.Name;
+print a.Next.Next == a;
+a.Next.Rename("c");
+print a.Next.Name;
+print items[1] == items;
+print byName["self"] == byName;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "b\ntrue\nc\ntrue\ntrue\n"
+	if stdOut.String() != want {
+		t.Errorf("printed %q, want %q", stdOut.String(), want)
+	}
+	if b.Name != "c" {
+		t.Errorf("b.Name = %q, want c", b.Name)
+	}
+
+	if err := lox.Eval(`
+class Node {}
+var x = Node();
+var y = Node();
+x.Name = "x";
+x.Next = y;
+y.Name = "y";
+y.Next = x;
+`); err != nil {
+		t.Fatal(err)
+	}
+	x, _ := lox.GetGlobal("x")
+	var goX *node
+	if err := lox.FromValue(x, &goX); err != nil {
+		t.Fatal(err)
+	}
+	if goX.Next.Name != "y" || goX.Next.Next != goX {
+		t.Errorf("x.Next = %+v, want y pointing back to x", goX.Next)
+	}
+}
+
+func TestCallable(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut})
+	var handlers []*Callable
+	err := lox.SetGlobal("on", func(handler Value) error {
+		callable, ok := lox.AsCallable(handler)
+		if !ok {
+			return errors.New("handler must be a function")
+		}
+		handlers = append(handlers, callable)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = lox.Eval(`
+class Counter {
+  init() { this.count = 0; }
+  add(n) { this.count = this.count + n; return this.count; }
+}
+var counter = Counter();
+on(counter.add);
+fun makeGreeter(greeting) {
+  fun greet(name) { return greeting + ", " + name; }
+  return greet;
+}
+on(makeGreeter("hello"));
+fun fun_that_fails() { return nil + 1; }
+on(1);
+`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "handler must be a function" {
+		t.Fatalf("got %v", err)
+	}
+	if len(handlers) != 2 {
+		t.Fatalf("registered %v handlers, want 2", len(handlers))
+	}
+
+	ctx := context.Background()
+	for index := 1; index <= 2; index++ {
+		result, err := handlers[0].Call(ctx, 5)
+		if err != nil || result != float64(5*index) {
+			t.Errorf("counter.add(5) = %v, %v, want %v", result, err, 5*index)
+		}
+	}
+	if result, err := handlers[1].Call(ctx, "world"); err != nil || result != "hello, world" {
+		t.Errorf("greet(world) = %v, %v", result, err)
+	}
+	if _, err := handlers[1].Call(ctx); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "expected 1 arguements but got 0" {
+		t.Errorf("greet() = %v, want an arity error", err)
+	}
+
+	fails, err := lox.Callable("fun_that_fails")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fails.Call(ctx); !errors.As(err, &runtimeErr) || len(runtimeErr.Trace) != 1 {
+		t.Errorf("got %v, want a runtime error in fun_that_fails", err)
+	}
+	if _, err := lox.Callable("counter"); err == nil {
+		t.Error("an instance was returned as callable")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := handlers[0].Call(cancelled, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("call with a cancelled context returned %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		source  string
+		code    string
+		message string
+	}{
+		{"statements", Options{MaxStatements: 100}, `while (true) {}`, e.CodeStatementLimit, "exceeded the limit of 100 statements"},
+		{"call depth", Options{MaxCallDepth: 50}, `fun f(n) { return f(n + 1); } f(0);`, e.CodeStackOverflow, "Stack overflow"},
+		{"default call depth", Options{}, `fun f(n) { return f(n + 1); } f(0);`, e.CodeStackOverflow, "Stack overflow"},
+		{"timeout", Options{Timeout: 10 * time.Millisecond}, `while (true) {}`, e.CodeTimeout, "execution timed out"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lox := New(test.options)
+			var runtimeErr *RuntimeError
+			if err := lox.Eval(test.source); !errors.As(err, &runtimeErr) {
+				t.Fatalf("got %v, want a runtime error", err)
+			}
+			if runtimeErr.Diagnostic.Code != test.code || runtimeErr.Diagnostic.Message != test.message {
+				t.Errorf("got %v %q, want %v %q", runtimeErr.Diagnostic.Code, runtimeErr.Diagnostic.Message, test.code, test.message)
+			}
+			//the budget is per run so the interpreter is still usable
+			if err := lox.Eval(`var ok = true;`); err != nil {
+				t.Errorf("running again after the limit: %v", err)
+			}
+		})
+	}
+
+	lox := New(Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	lox.SetGlobal("stop", cancel)
+	var runtimeErr *RuntimeError
+	err := lox.EvalContext(ctx, `var n = 0; while (true) { n = n + 1; if (n == 10) stop(); }`)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Code != e.CodeCancelled {
+		t.Errorf("got %v, want the run to be cancelled", err)
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		source  string
+		message string
+	}{
+		{"instances", Options{InstanceQuota: 10}, `class A {} while (true) A();`, "exceeded the quota of 10 instances"},
+		{"closures", Options{ClosureQuota: 10}, `while (true) { fun f() {} }`, "exceeded the quota of 10 closures"},
+		{"environments", Options{EnvironmentQuota: 10}, `fun f() {} while (true) f();`, "exceeded the quota of 10 environments"},
+		{"string bytes", Options{StringByteQuota: 100}, `var s = "ab"; while (true) s = s + s;`, "exceeded the quota of 100 string bytes"},
+		{"list push", Options{ElementQuota: 100}, `var l = []; while (true) l.push(l);`, "exceeded the quota of 100 elements"},
+		{"list literals", Options{ElementQuota: 100}, `while (true) [];`, "exceeded the quota of 100 elements"},
+		{"list slices", Options{ElementQuota: 100}, `var l = [1, 2, 3]; while (true) l.slice(0, 3);`, "exceeded the quota of 100 elements"},
+		{"map entries", Options{ElementQuota: 100}, `var m = {}; var n = 0; while (true) { m[n] = n; n = n + 1; }`, "exceeded the quota of 100 elements"},
+		{"map literals", Options{ElementQuota: 100}, `while (true) ({});`, "exceeded the quota of 100 elements"},
+		{"map keys", Options{ElementQuota: 100}, `var m = {"a": 1}; while (true) m.keys();`, "exceeded the quota of 100 elements"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lox := New(test.options)
+			var runtimeErr *RuntimeError
+			if err := lox.Eval(test.source); !errors.As(err, &runtimeErr) {
+				t.Fatalf("got %v, want a runtime error", err)
+			}
+			if runtimeErr.Diagnostic.Code != e.CodeQuota || runtimeErr.Diagnostic.Message != test.message {
+				t.Errorf("got %v %q, want %q", runtimeErr.Diagnostic.Code, runtimeErr.Diagnostic.Message, test.message)
+			}
+		})
+	}
+
+	lox := New(Options{})
+	if err := lox.Eval(`class A { m() {} } var a = A(); a.m(); var s = "ab" + "cd"; var l = [1, 2]; l.push(3); var m = {"a": 1}; m["a"] = 2; m["b"] = 3;`); err != nil {
+		t.Fatal(err)
+	}
+	want := interpreter.Allocations{Instances: 1, Closures: 2, Environments: 2, StringBytes: 4, Elements: 7}
+	if got := lox.Allocations(); got != want {
+		t.Errorf("allocations = %+v, want %+v", got, want)
+	}
+
+	//a quota error while making a subclass's methods leaves later runs at the top level
+	lox = New(Options{ClosureQuota: 2})
+	if err := lox.Eval(`class A { m() {} } class B < A { m() {} n() {} }`); err == nil {
+		t.Fatal("got no error, want the closure quota error")
+	}
+	if err := lox.Eval(`var x = 1;`); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lox.GetGlobal("x"); !ok {
+		t.Error("x was not defined as a global after the quota error")
+	}
+
+	lox = New(Options{ElementQuota: 3})
+	if err := lox.SetGlobal("l", []int{1, 2, 3}); err == nil || err.Error() != "golox: exceeded the quota of 3 elements" {
+		t.Errorf("got %v, want the quota error", err)
+	}
+	lox = New(Options{ElementQuota: 3})
+	if err := lox.SetGlobal("m", map[string]int{"a": 1, "b": 2, "c": 3}); err == nil || err.Error() != "golox: exceeded the quota of 3 elements" {
+		t.Errorf("got %v, want the quota error", err)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	var runtimeErr *RuntimeError
+	lox := New(Options{})
+	if err := lox.Eval(`clock();`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "undefined variable 'clock'" {
+		t.Errorf("got %v, want clock to be undefined", err)
+	}
+	lox.DefineNative(&NativeFunction{
+		Name:     "load",
+		Requires: CapabilityFilesystem,
+		Function: func(in *interpreter.Interpreter, arguements []Value) (Value, error) {
+			return "loaded", nil
+		},
+	})
+	if err := lox.Eval(`load();`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "load needs filesystem access, which is denied" {
+		t.Errorf("got %v, want filesystem access to be denied", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "note.txt")
+	var stdOut bytes.Buffer
+	lox = New(Options{Stdout: &stdOut, Capabilities: CapabilityFilesystem | CapabilityClock})
+	lox.SetGlobal("path", path)
+	if err := lox.Eval(`writeFile(path, "hi"); print readFile(path); print clock() > 0;`); err != nil {
+		t.Fatal(err)
+	}
+	if stdOut.String() != "hi\ntrue\n" {
+		t.Errorf("printed %q", stdOut.String())
+	}
+	if _, ok := lox.GetGlobal("getenv"); ok {
+		t.Error("getenv is defined without the environment capability")
+	}
+}
+
+func TestExit(t *testing.T) {
+	var stdOut bytes.Buffer
+	lox := New(Options{Stdout: &stdOut, Capabilities: CapabilityProcess})
+	var exit *Exit
+	err := lox.Eval(`
+fun stop() {
+  while (true) exit(3);
+}
+print "before";
+stop();
+print "after";
+`)
+	if !errors.As(err, &exit) || exit.Code != 3 {
+		t.Fatalf("got %v, want exit status 3", err)
+	}
+	if stdOut.String() != "before\n" {
+		t.Errorf("printed %q, want %q", stdOut.String(), "before\n")
+	}
+	if _, err := lox.Call("stop"); !errors.As(err, &exit) || exit.Code != 3 {
+		t.Errorf("got %v, want exit status 3", err)
+	}
+	//the interpreter can still be used after a script exits
+	if err := lox.Eval(`print "again";`); err != nil {
+		t.Fatal(err)
+	}
+
+	var runtimeErr *RuntimeError
+	if err := lox.Eval(`exit(1.5);`); !errors.As(err, &runtimeErr) || runtimeErr.Diagnostic.Message != "exit code must be a whole number" {
+		t.Errorf("got %v, want a runtime error", err)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	run := func() string {
+		var stdOut bytes.Buffer
+		lox := New(Options{
+			Stdout:       &stdOut,
+			Capabilities: CapabilityClock,
+			Clock:        func() time.Time { return time.Unix(1700000000, 0) },
+			RandSource:   rand.NewSource(42),
+		})
+		lox.SetGlobal("scores", map[string]int{"c": 3, "a": 1, "b": 2, "d": 4})
+		err := lox.Eval(`
+print clock();
+print random();
+print random();
+var keys = scores.keys();
+for (var i = 0; i < keys.len(); i = i + 1) print keys[i];
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stdOut.String()
+	}
+	first := run()
+	if !strings.HasPrefix(first, "1.7e+09\n") || !strings.HasSuffix(first, "a\nb\nc\nd\n") {
+		t.Errorf("printed %q", first)
+	}
+	for index := 0; index < 5; index++ {
+		if again := run(); again != first {
+			t.Fatalf("run %v printed %q, first run printed %q", index+2, again, first)
+		}
+	}
+}
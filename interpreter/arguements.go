@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"fmt"
+
+	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// describes how many arguements a callable takes for an arity error
+func arityMessage(least int, most int, got int) string {
+	if most == least {
+		return fmt.Sprintf("expected %v arguements but got %v", least, got)
+	}
+	if most == noLimit {
+		return fmt.Sprintf("expected at least %v arguements but got %v", least, got)
+	}
+	return fmt.Sprintf("expected %v to %v arguements but got %v", least, most, got)
+}
+
+// returns the declaration whose parameters the arguements of a call are matched to, nil if the callee is not a Lox
+// function or a class with an initialiser
+func declarationOf(callee interface{}) *abs.FunctionStmt {
+	switch callee := callee.(type) {
+	case *loxFunction:
+		return callee.Declaration
+	case *loxClass:
+		if initialiser := callee.findMethod("init"); initialiser != nil {
+			return initialiser.Declaration
+		}
+	}
+	return nil
+}
+
+// puts the values of named arguements in the places of the parameters they name, after the positional arguements.
+// parameters left out take their defaults, so leaving out one without a default is an error at the paren
+func (i *Interpreter) placeNamedArguements(callee interface{}, positional []interface{}, names []abs.NamedArguement, values []interface{}, paren t.Token) []interface{} {
+	if _, ok := callee.(loxCallable); !ok {
+		//left for call to report
+		return positional
+	}
+	declaration := declarationOf(callee)
+	if declaration == nil {
+		i.error(paren, e.CodeNamedArguement, fmt.Sprintf("%v does not take named arguements", i.stringify(callee)))
+	}
+	arguements := append([]interface{}(nil), positional...)
+	for len(arguements) < len(declaration.Params) {
+		arguements = append(arguements, missingArguement{})
+	}
+	for index := 0; index < len(names); index++ {
+		name := names[index].Name
+		position := -1
+		for param := 0; param < len(declaration.Params); param++ {
+			if declaration.Params[param].Lexeme == name.Lexeme {
+				position = param
+				break
+			}
+		}
+		if position == -1 {
+			i.error(name, e.CodeNamedArguement, fmt.Sprintf("no parameter named '%v'", name.Lexeme))
+		}
+		if _, missing := arguements[position].(missingArguement); !missing {
+			i.error(name, e.CodeNamedArguement, fmt.Sprintf("parameter '%v' is given more than once", name.Lexeme))
+		}
+		arguements[position] = values[index]
+	}
+	for index := 0; index < len(declaration.Params) && declaration.Defaults[index] == nil; index++ {
+		if _, missing := arguements[index].(missingArguement); missing {
+			i.error(paren, e.CodeArity, fmt.Sprintf("missing arguement for parameter '%v'", declaration.Params[index].Lexeme))
+		}
+	}
+	return arguements
+}
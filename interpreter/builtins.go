@@ -0,0 +1,83 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// the natives every interpreter starts with, apart from those needing capabilities it was not given
+var builtins = []*NativeFunction{
+	{
+		Name:     "clock",
+		Requires: CapabilityClock,
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			return float64(interpreter.Clock().UnixMilli() / 1000), nil
+		},
+	},
+	{
+		Name: "random",
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			return interpreter.Random.Float64(), nil
+		},
+	},
+	{
+		Name:     "readFile",
+		Arity:    1,
+		Requires: CapabilityFilesystem,
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			path, ok := arguements[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("path must be a string")
+			}
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return string(contents), nil
+		},
+	},
+	{
+		Name:     "writeFile",
+		Arity:    2,
+		Requires: CapabilityFilesystem,
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			path, ok := arguements[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("path must be a string")
+			}
+			contents, ok := arguements[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("contents must be a string")
+			}
+			return nil, os.WriteFile(path, []byte(contents), 0o644)
+		},
+	},
+	{
+		Name:     "getenv",
+		Arity:    1,
+		Requires: CapabilityEnvironment,
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			name, ok := arguements[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("name must be a string")
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				return value, nil
+			}
+			return nil, nil
+		},
+	},
+	{
+		Name:     "exit",
+		Arity:    1,
+		Requires: CapabilityProcess,
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			code, ok := arguements[0].(float64)
+			if !ok || code != math.Trunc(code) {
+				return nil, fmt.Errorf("exit code must be a whole number")
+			}
+			return nil, &Exit{Code: int(code)}
+		},
+	},
+}
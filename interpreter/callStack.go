@@ -0,0 +1,81 @@
+package interpreter
+
+import (
+	"fmt"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// the most frames a runtime error lists. deeper stacks keep the innermost and outermost frames and skip the middle
+const maxTraceFrames = 20
+
+// Frame is a Lox call in progress. Class is set for methods and for calls that construct an instance, CallSite is
+// the closing paren of the call that made the frame
+type Frame struct {
+	Function string
+	Class    string
+	CallSite t.Span
+}
+
+func (f Frame) String() string {
+	if f.Class == "" {
+		return fmt.Sprintf("%v()", f.Function)
+	}
+	if f.Function == "" {
+		return fmt.Sprintf("%v()", f.Class)
+	}
+	return fmt.Sprintf("%v.%v()", f.Class, f.Function)
+}
+
+// StackTrace returns the Lox calls in progress, innermost first
+func (i *Interpreter) StackTrace() []Frame {
+	trace := make([]Frame, len(i.frames))
+	for index := 0; index < len(i.frames); index++ {
+		trace[index] = i.frames[len(i.frames)-1-index]
+	}
+	return trace
+}
+
+// LastErrorTrace returns the stack trace, innermost first, captured when the last runtime error was raised
+func (i *Interpreter) LastErrorTrace() []Frame {
+	return i.lastErrorTrace
+}
+
+func (i *Interpreter) frameFor(callee loxCallable, callSite t.Token) Frame {
+	frame := Frame{CallSite: callSite.Span}
+	switch callee := callee.(type) {
+	case *loxFunction:
+		frame.Function = callee.name()
+		frame.Class = callee.className
+	case *loxClass:
+		frame.Class = callee.Name
+		if callee.findMethod("init") != nil {
+			frame.Function = "init"
+		}
+	case *NativeFunction:
+		frame.Function = callee.Name
+		frame.Class = callee.className
+	}
+	return frame
+}
+
+// turns a stack trace into diagnostic notes, each one showing where a frame was called from
+func traceNotes(trace []Frame) []e.Note {
+	var notes []e.Note
+	for index := 0; index < len(trace); index++ {
+		if len(trace) > maxTraceFrames && index == maxTraceFrames/2 {
+			skipped := len(trace) - maxTraceFrames
+			notes = append(notes, e.Note{Message: fmt.Sprintf("... %v more frames", skipped)})
+			index += skipped - 1
+			continue
+		}
+		caller := "script"
+		if index+1 < len(trace) {
+			caller = trace[index+1].String()
+		}
+		frame := trace[index]
+		notes = append(notes, e.Note{Message: fmt.Sprintf("in %v, called from %v", frame, caller), Span: frame.CallSite})
+	}
+	return notes
+}
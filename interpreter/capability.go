@@ -0,0 +1,49 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities is a set of the kinds of access to the world outside the program that natives can need. a native
+// whose capabilities the interpreter was not given is left out of the globals, or raises a runtime error when called
+// if it was defined by the host
+type Capabilities uint8
+
+const (
+	CapabilityFilesystem Capabilities = 1 << iota
+	CapabilityEnvironment
+	CapabilityClock
+	CapabilityProcess
+
+	NoCapabilities  Capabilities = 0
+	AllCapabilities              = CapabilityFilesystem | CapabilityEnvironment | CapabilityClock | CapabilityProcess
+)
+
+var capabilityNames = []string{"filesystem", "environment", "clock", "process"}
+
+// Has reports whether every capability in other is in the set
+func (c Capabilities) Has(other Capabilities) bool {
+	return c&other == other
+}
+
+func (c Capabilities) String() string {
+	if c == NoCapabilities {
+		return "none"
+	}
+	var names []string
+	for index := 0; index < len(capabilityNames); index++ {
+		if c.Has(1 << index) {
+			names = append(names, capabilityNames[index])
+		}
+	}
+	if unknown := c &^ AllCapabilities; unknown != 0 {
+		names = append(names, fmt.Sprintf("Capabilities(%#x)", uint8(unknown)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Capabilities returns the capabilities the interpreter was given
+func (i *Interpreter) Capabilities() Capabilities {
+	return i.capabilities
+}
@@ -2,35 +2,42 @@ package interpreter
 
 import (
 	"fmt"
+	"reflect"
 
 	t "github.com/constwhite/golox-interpreter/token"
 )
 
+// classes, instances and functions are always handled through pointers so a value keeps its identity however
+// many variables or fields refer to it
 type loxClass struct {
 	Name       string
-	methods    map[string]loxFunction
+	methods    map[string]*loxFunction
 	SuperClass *loxClass
+	//methods written in Go, which take the instance as their first arguement. set on the classes made for Go types
+	natives map[string]*NativeFunction
+	//the Go type whose values the class's instances stand in for, nil for classes declared in Lox
+	goType reflect.Type
 }
 
-func (c loxClass) call(interpreter *Interpreter, args []interface{}) interface{} {
-	instance := loxInstance{Class: c}
+func (c *loxClass) call(interpreter *Interpreter, args []interface{}) interface{} {
+	instance := interpreter.newInstance(c)
 	initialiser := c.findMethod("init")
 	if initialiser != nil {
-		initialiser.bind(&instance).call(interpreter, args)
+		initialiser.bind(interpreter, instance).call(interpreter, args)
 	}
 	return instance
 }
 
-func (c loxClass) arity() int {
+func (c *loxClass) arity() (int, int) {
 	initialiser := c.findMethod("init")
 	if initialiser == nil {
-		return 0
+		return 0, 0
 	}
 	return initialiser.arity()
 }
-func (c loxClass) findMethod(name string) *loxFunction {
+func (c *loxClass) findMethod(name string) *loxFunction {
 	if method, ok := c.methods[name]; ok {
-		return &method
+		return method
 	}
 	if c.SuperClass != nil {
 		return c.SuperClass.findMethod(name)
@@ -38,23 +45,32 @@ func (c loxClass) findMethod(name string) *loxFunction {
 	return nil
 }
 
-// type Instance interface {
-// 	Get(Interpreter, t.Token) (interface{}, error)
-// }
+func (c *loxClass) String() string {
+	return c.Name
+}
 
 type loxInstance struct {
-	Class  loxClass
+	Class  *loxClass
 	Fields map[string]interface{}
+	//the Go struct pointer the instance stands in for when its class was made for a Go type
+	goValue reflect.Value
+}
+
+func (in *loxInstance) String() string {
+	return fmt.Sprintf("%v instance", in.Class.Name)
 }
 
-func (in *loxInstance) get(name t.Token) (interface{}, error) {
+func (in *loxInstance) get(interpreter *Interpreter, name t.Token) (interface{}, error) {
 	if field, ok := in.Fields[name.Lexeme]; ok {
 		return field, nil
 	}
 
 	method := in.Class.findMethod(name.Lexeme)
 	if method != nil {
-		return method.bind(in), nil
+		return method.bind(interpreter, in), nil
+	}
+	if native, ok := in.Class.natives[name.Lexeme]; ok {
+		return native.bind(in), nil
 	}
 	err := fmt.Errorf("undefined property '%v'", name.Lexeme)
 	return nil, err
@@ -1,22 +1,32 @@
 package interpreter
 
 import (
+	"fmt"
+
 	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
 	env "github.com/constwhite/golox-interpreter/environment"
 )
 
 type loxCallable interface {
 	call(*Interpreter, []interface{}) interface{}
-	arity() int
+	//the fewest and most arguements the callable takes, the most being noLimit when there is no limit
+	arity() (int, int)
 }
 
+const noLimit = -1
+
+// fills the place of a parameter left out of a call that names later parameters, so it takes its default
+type missingArguement struct{}
+
 type loxFunction struct {
-	Declaration   abs.FunctionStmt
+	Declaration   *abs.FunctionStmt
 	Closure       *env.Environment
 	isInitialiser bool
+	//name of the class a method was declared in, empty for functions
+	className string
 }
 
-func (f loxFunction) call(interpreter *Interpreter, args []interface{}) (returnVal interface{}) {
+func (f *loxFunction) call(interpreter *Interpreter, args []interface{}) (returnVal interface{}) {
 	defer func() {
 		if err := recover(); err != nil {
 			if v, ok := err.(returnValue); ok {
@@ -31,9 +41,23 @@ func (f loxFunction) call(interpreter *Interpreter, args []interface{}) (returnV
 			panic(err)
 		}
 	}()
-	env := env.NewEnvironment(f.Closure)
-	for i := 0; i < len(f.Declaration.Params); i++ {
-		env.Define(f.Declaration.Params[i].Lexeme, args[i])
+	env := interpreter.newEnvironment(f.Closure)
+	params := f.Declaration.Params
+	for i := 0; i < len(params); i++ {
+		if i < len(args) {
+			if _, missing := args[i].(missingArguement); !missing {
+				env.Define(params[i].Lexeme, args[i])
+				continue
+			}
+		}
+		env.Define(params[i].Lexeme, interpreter.evaluateIn(f.Declaration.Defaults[i], env))
+	}
+	if f.Declaration.Rest != nil {
+		rest := []interface{}{}
+		if len(args) > len(params) {
+			rest = append(rest, args[len(params):]...)
+		}
+		env.Define(f.Declaration.Rest.Lexeme, interpreter.newList(interpreter.allocationSite(), rest))
 	}
 	interpreter.executeBlock(f.Declaration.Body, env)
 	if f.isInitialiser {
@@ -42,11 +66,30 @@ func (f loxFunction) call(interpreter *Interpreter, args []interface{}) (returnV
 	return nil
 }
 
-func (f loxFunction) arity() int {
-	return len(f.Declaration.Params)
+func (f *loxFunction) arity() (int, int) {
+	required := 0
+	for required < len(f.Declaration.Params) && f.Declaration.Defaults[required] == nil {
+		required++
+	}
+	if f.Declaration.Rest != nil {
+		return required, noLimit
+	}
+	return required, len(f.Declaration.Params)
 }
-func (f loxFunction) bind(instance *loxInstance) loxFunction {
-	environment := *env.NewEnvironment(f.Closure)
+func (f *loxFunction) bind(interpreter *Interpreter, instance *loxInstance) *loxFunction {
+	environment := interpreter.newEnvironment(f.Closure)
 	environment.Define("this", instance)
-	return loxFunction{Declaration: f.Declaration, Closure: &environment, isInitialiser: f.isInitialiser}
+	return interpreter.newFunction(f.Declaration, environment, f.isInitialiser, f.className)
+}
+
+func (f *loxFunction) String() string {
+	return fmt.Sprintf("<fn %v>", f.name())
+}
+
+// the name the function was declared with, or anonymous for a function expression
+func (f *loxFunction) name() string {
+	if f.Declaration.Name.Lexeme == "" {
+		return "anonymous"
+	}
+	return f.Declaration.Name.Lexeme
 }
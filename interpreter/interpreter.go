@@ -1,9 +1,13 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"reflect"
+	"time"
 
 	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
 	env "github.com/constwhite/golox-interpreter/environment"
@@ -12,58 +16,140 @@ import (
 )
 
 type Interpreter struct {
-	stdErr          io.Writer
-	stdOut          io.Writer
-	HasRuntimeError bool
-	RuntimeError    runtimeError
-	Environment     *env.Environment
-	Globals         *env.Environment
-	Locals          map[abs.Expr]int
+	stdOut      io.Writer
+	Environment *env.Environment
+	Globals     *env.Environment
+	Locals      map[abs.Expr]int
+	//the Lox calls in progress, outermost first
+	frames         []Frame
+	lastErrorTrace []Frame
+	//the classes made for Go types passed in through ToValue
+	goClasses map[reflect.Type]*loxClass
+	Limits    Limits
+	//the context of the run in progress, nil between runs
+	ctx context.Context
+	//statements executed so far in the run in progress
+	statements   int
+	allocations  Allocations
+	capabilities Capabilities
+	//where natives get the time and random numbers from. replace them with a fixed clock and a seeded source so
+	//a program prints the same thing every run
+	Clock  func() time.Time
+	Random *rand.Rand
 }
 
 type runtimeError struct {
 	error
-	Line int
+	Code  string
+	Span  t.Span
+	Trace []Frame
 }
 
-func (rte *runtimeError) Error() error {
-	return rte.error
+func (rte *runtimeError) diagnostic() e.Diagnostic {
+	diagnostic := e.NewError(e.PhaseRuntime, rte.Code, rte.error.Error(), rte.Span)
+	diagnostic.Notes = traceNotes(rte.Trace)
+	return diagnostic
 }
 
-func NewInterpreter(stdErr io.Writer, stdOut io.Writer) *Interpreter {
+// makes an interpreter whose natives can only reach what capabilities allows. builtins needing anything else are
+// left out of the globals
+func NewInterpreter(stdOut io.Writer, capabilities Capabilities) *Interpreter {
 	global := env.NewEnvironment(nil)
-	global.Define("clock", Clock{})
-	return &Interpreter{stdErr: stdErr, stdOut: stdOut, Environment: global, Globals: global, Locals: make(map[abs.Expr]int)}
+	interpreter := &Interpreter{
+		stdOut:       stdOut,
+		Environment:  global,
+		Globals:      global,
+		Locals:       make(map[abs.Expr]int),
+		capabilities: capabilities,
+		Clock:        time.Now,
+		Random:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for index := 0; index < len(builtins); index++ {
+		if capabilities.Has(builtins[index].Requires) {
+			interpreter.DefineNative(builtins[index])
+		}
+	}
+	return interpreter
 }
 
-func (i *Interpreter) Interpret(stmtList []abs.Stmt) (HasRuntimeError bool) {
-	defer func() {
-		if err := recover(); err != nil {
-			if _, ok := err.(runtimeError); ok {
-				HasRuntimeError = true
-				return
-			} else {
-				panic(err)
-			}
-		}
-	}()
+// Exit is returned by the entry points when the program calls exit, or a native returns one as its error. the
+// program stops as it would on a runtime error, leaving the caller to decide whether to end the process with Code
+type Exit struct {
+	Code int
+}
+
+func (x *Exit) Error() string {
+	return fmt.Sprintf("exit status %v", x.Code)
+}
+
+// runs the statements, stopping at the first runtime error which is returned as a diagnostic
+func (i *Interpreter) Interpret(stmtList []abs.Stmt) ([]e.Diagnostic, *Exit) {
+	return i.InterpretContext(context.Background(), stmtList)
+}
+
+// runs the statements like Interpret, stopping with a runtime error if ctx is cancelled
+func (i *Interpreter) InterpretContext(ctx context.Context, stmtList []abs.Stmt) (diagnostics []e.Diagnostic, exit *Exit) {
+	defer i.begin(ctx)()
+	defer i.recoverRuntimeError(&diagnostics, &exit)
 	for index := 0; index < len(stmtList); index++ {
 		stmt := stmtList[index]
 		i.execute(stmt)
 	}
-	return HasRuntimeError
+	return nil, nil
+
+}
+
+// evaluates the expression of an expression statement and returns its value as it would be printed. used by the REPL to echo bare expressions
+func (i *Interpreter) Evaluate(stmt *abs.ExpressionStmt) (value string, diagnostics []e.Diagnostic, exit *Exit) {
+	defer i.begin(context.Background())()
+	defer i.recoverRuntimeError(&diagnostics, &exit)
+	return i.stringify(i.evaluate(stmt.Expression)), nil, nil
+}
+
+// calls a Lox function, class or bound method from Go. name stands in for the call site in errors and stack traces
+func (i *Interpreter) Call(name string, callee interface{}, args []interface{}) (interface{}, []e.Diagnostic, *Exit) {
+	return i.CallContext(context.Background(), name, callee, args)
+}
 
+// calls like Call, stopping with a runtime error if ctx is cancelled
+func (i *Interpreter) CallContext(ctx context.Context, name string, callee interface{}, args []interface{}) (result interface{}, diagnostics []e.Diagnostic, exit *Exit) {
+	defer i.begin(ctx)()
+	defer i.recoverRuntimeError(&diagnostics, &exit)
+	callSite := t.Token{TokenType: t.TokenIdentifier, Lexeme: name}
+	return i.call(callee, args, callSite), nil, nil
+}
+
+// IsCallable reports whether a value is a function, class or bound method that Call can call
+func IsCallable(value Value) bool {
+	_, ok := value.(loxCallable)
+	return ok
+}
+
+// recovers a runtimeError thrown while running and stores it in diagnostics, or an Exit and stores it in exit. any
+// other panic is passed on. must be deferred directly so recover sees the panic
+func (i *Interpreter) recoverRuntimeError(diagnostics *[]e.Diagnostic, exit **Exit) {
+	if err := recover(); err != nil {
+		if rte, ok := err.(runtimeError); ok {
+			*diagnostics = []e.Diagnostic{rte.diagnostic()}
+			return
+		}
+		if x, ok := err.(*Exit); ok {
+			*exit = x
+			return
+		}
+		panic(err)
+	}
 }
 
 //expression visitors
 
-func (i *Interpreter) VisitLiteralExpr(expr abs.LiteralExpr) interface{} {
+func (i *Interpreter) VisitLiteralExpr(expr *abs.LiteralExpr) interface{} {
 	return expr.Value
 }
-func (i *Interpreter) VisitGroupingExpr(expr abs.GroupingExpr) interface{} {
+func (i *Interpreter) VisitGroupingExpr(expr *abs.GroupingExpr) interface{} {
 	return i.evaluate(expr.Expression)
 }
-func (i *Interpreter) VisitUnaryExpr(expr abs.UnaryExpr) interface{} {
+func (i *Interpreter) VisitUnaryExpr(expr *abs.UnaryExpr) interface{} {
 	right := i.evaluate(expr.Right)
 	switch expr.Operator.TokenType {
 	case t.TokenBang:
@@ -76,7 +162,7 @@ func (i *Interpreter) VisitUnaryExpr(expr abs.UnaryExpr) interface{} {
 	}
 	return nil
 }
-func (i *Interpreter) VisitBinaryExpr(expr abs.BinaryExpr) interface{} {
+func (i *Interpreter) VisitBinaryExpr(expr *abs.BinaryExpr) interface{} {
 	left := i.evaluate(expr.Left)
 	right := i.evaluate(expr.Right)
 
@@ -114,11 +200,9 @@ func (i *Interpreter) VisitBinaryExpr(expr abs.BinaryExpr) interface{} {
 		_, leftIsString := left.(string)
 		_, rightIsString := right.(string)
 		if leftIsString && rightIsString {
-			return left.(string) + right.(string)
+			return i.concatenate(expr.Operator, left.(string), right.(string))
 		}
-		err := runtimeError{error: fmt.Errorf("operands must be numbers or string"), Line: expr.Operator.Line}
-		e.RuntimeError(i.stdErr, err.Error(), err.Line)
-		panic(err)
+		i.error(expr.Operator, e.CodeOperandType, "operands must be two numbers or two strings")
 	case t.TokenSlash:
 		i.checkNumberOperands(expr.Operator, left, right)
 		return left.(float64) / right.(float64)
@@ -133,31 +217,28 @@ func (i *Interpreter) VisitBinaryExpr(expr abs.BinaryExpr) interface{} {
 	return nil
 }
 
-func (i *Interpreter) VisitVariableExpr(expr abs.VariableExpr) interface{} {
+func (i *Interpreter) VisitVariableExpr(expr *abs.VariableExpr) interface{} {
 	// value, err := i.Environment.Get(expr.Name)
 	value, err := i.lookupVariable(expr.Name, expr)
 	if err != nil {
-		runtimeErr := runtimeError{error: err, Line: expr.Name.Line}
-		i.RuntimeError = runtimeErr
-
+		i.error(expr.Name, e.CodeUndefinedVariable, fmt.Sprintf("undefined variable '%v'", expr.Name.Lexeme))
 	}
 	return value
 }
-func (i *Interpreter) VisitAssignExpr(expr abs.AssignExpr) interface{} {
+func (i *Interpreter) VisitAssignExpr(expr *abs.AssignExpr) interface{} {
 	value := i.evaluate(expr.Value)
 	distance, ok := i.Locals[expr]
 	if ok {
 		i.Environment.AssignAt(distance, expr.Name, value)
 	} else {
 		if err := i.Globals.Assign(expr.Name, value); err != nil {
-			runtimeErr := runtimeError{error: err, Line: expr.Name.Line}
-			i.RuntimeError = runtimeErr
+			i.error(expr.Name, e.CodeUndefinedVariable, fmt.Sprintf("undefined variable '%v'", expr.Name.Lexeme))
 		}
 	}
 	return value
 }
 
-func (i *Interpreter) VisitLogicalExpr(expr abs.LogicalExpr) interface{} {
+func (i *Interpreter) VisitLogicalExpr(expr *abs.LogicalExpr) interface{} {
 	left := i.evaluate(expr.Left)
 	if expr.Operator.TokenType == t.TokenOr {
 		if i.isTruthy(left) {
@@ -171,94 +252,147 @@ func (i *Interpreter) VisitLogicalExpr(expr abs.LogicalExpr) interface{} {
 	return i.evaluate(expr.Right)
 }
 
-func (i *Interpreter) VisitCallExpr(expr abs.CallExpr) interface{} {
+func (i *Interpreter) VisitCallExpr(expr *abs.CallExpr) interface{} {
 	callee := i.evaluate(expr.Callee)
 	var arguements []interface{}
 	for index := 0; index < len(expr.Arguements); index++ {
 		arguement := expr.Arguements[index]
 		arguements = append(arguements, i.evaluate(arguement))
 	}
-	function, callable := callee.(loxCallable)
-	if !callable {
-		err := runtimeError{error: errors.New("can only call funtions and classes"), Line: expr.Paren.Line}
-		i.RuntimeError = err
-		return nil
-	}
-	if len(arguements) != function.arity() {
-		err := runtimeError{error: fmt.Errorf("expected %v arguements but got %v", function.arity(), len(arguements)), Line: expr.Paren.Line}
-		i.RuntimeError = err
-		return nil
+	if len(expr.NamedArguements) > 0 {
+		named := make([]interface{}, len(expr.NamedArguements))
+		for index := 0; index < len(expr.NamedArguements); index++ {
+			named[index] = i.evaluate(expr.NamedArguements[index].Value)
+		}
+		arguements = i.placeNamedArguements(callee, arguements, expr.NamedArguements, named, expr.Paren)
 	}
-	return function.call(i, arguements)
+	return i.call(callee, arguements, expr.Paren)
 }
 
-func (i *Interpreter) VisitGetExpr(expr abs.GetExpr) interface{} {
+func (i *Interpreter) VisitGetExpr(expr *abs.GetExpr) interface{} {
 	object := i.evaluate(expr.Object)
-	instance, isInstance := object.(loxInstance)
-	if !isInstance {
-		err := runtimeError{error: errors.New("only instances have properties"), Line: expr.Name.Line}
-		i.RuntimeError = err
-		return nil
+	var property interface{}
+	var err error
+	switch object := object.(type) {
+	case *loxInstance:
+		property, err = object.get(i, expr.Name)
+	case *loxList:
+		property, err = object.get(expr.Name)
+	case *loxMap:
+		property, err = object.get(expr.Name)
+	default:
+		i.error(expr.Name, e.CodeNotInstance, "only instances have properties")
 	}
-	property, err := instance.get(expr.Name)
 	if err != nil {
-		i.RuntimeError = runtimeError{error: err, Line: expr.Name.Line}
-		return nil
+		i.error(expr.Name, e.CodeUndefinedProperty, err.Error())
 	}
 	return property
 }
 
-func (i *Interpreter) VisitSetExpr(expr abs.SetExpr) interface{} {
+func (i *Interpreter) VisitSetExpr(expr *abs.SetExpr) interface{} {
 	object := i.evaluate(expr.Object)
-	instance, isInstance := object.(loxInstance)
+	instance, isInstance := object.(*loxInstance)
 	if !isInstance {
-		err := runtimeError{error: errors.New("only instances have fields"), Line: expr.Name.Line}
-		i.RuntimeError = err
-		return nil
+		i.error(expr.Name, e.CodeNotInstance, "only instances have fields")
 	}
 	value := i.evaluate(expr.Value)
 	instance.set(expr.Name, value)
 	return value
 }
 
-func (i *Interpreter) VisitSuperExpr(expr abs.SuperExpr) interface{} {
+func (i *Interpreter) VisitListExpr(expr *abs.ListExpr) interface{} {
+	elements := make([]interface{}, len(expr.Elements))
+	for index := 0; index < len(expr.Elements); index++ {
+		elements[index] = i.evaluate(expr.Elements[index])
+	}
+	return i.newList(expr.Bracket.Span, elements)
+}
+
+func (i *Interpreter) VisitMapExpr(expr *abs.MapExpr) interface{} {
+	m := i.newMap(expr.Brace.Span)
+	for index := 0; index < len(expr.Keys); index++ {
+		key := i.evaluate(expr.Keys[index])
+		i.setKey(expr.Brace.Span, m, key, i.evaluate(expr.Values[index]))
+	}
+	return m
+}
+
+func (i *Interpreter) VisitIndexExpr(expr *abs.IndexExpr) interface{} {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	switch object := object.(type) {
+	case *loxList:
+		return object.Elements[i.checkIndex(expr.Bracket, object, index)]
+	case *loxMap:
+		value, ok, err := object.lookup(index)
+		if err != nil {
+			i.errorAt(expr.Bracket.Span, e.CodeInvalidKey, err)
+		}
+		if !ok {
+			i.error(expr.Bracket, e.CodeUndefinedKey, fmt.Sprintf("undefined key %v", i.stringify(index)))
+		}
+		return value
+	}
+	i.error(expr.Bracket, e.CodeNotIndexable, "only lists and maps can be indexed")
+	return nil
+}
+
+func (i *Interpreter) VisitSetIndexExpr(expr *abs.SetIndexExpr) interface{} {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	switch object := object.(type) {
+	case *loxList:
+		position := i.checkIndex(expr.Bracket, object, index)
+		value := i.evaluate(expr.Value)
+		object.Elements[position] = value
+		return value
+	case *loxMap:
+		value := i.evaluate(expr.Value)
+		i.setKey(expr.Bracket.Span, object, index, value)
+		return value
+	}
+	i.error(expr.Bracket, e.CodeNotIndexable, "only lists and maps can be indexed")
+	return nil
+}
+
+func (i *Interpreter) VisitFunctionExpr(expr *abs.FunctionExpr) interface{} {
+	return i.newFunction(expr.Declaration, i.Environment, false, "")
+}
+
+func (i *Interpreter) VisitSuperExpr(expr *abs.SuperExpr) interface{} {
 	distance := i.Locals[expr]
 	superclass := i.Environment.GetAt(distance, "super").(*loxClass)
 	object := i.Environment.GetAt(distance-1, "this").(*loxInstance)
 	method := superclass.findMethod(expr.Method.Lexeme)
 	if method == nil {
-		err := runtimeError{error: fmt.Errorf("undefined property %v", expr.Method.Lexeme), Line: expr.Method.Line}
-		i.RuntimeError = err
-		return nil
+		i.error(expr.Method, e.CodeUndefinedProperty, fmt.Sprintf("undefined property '%v'", expr.Method.Lexeme))
 	}
-	return method.bind(object)
+	return method.bind(i, object)
 }
 
-func (i *Interpreter) VisitThisExpr(expr abs.ThisExpr) interface{} {
+func (i *Interpreter) VisitThisExpr(expr *abs.ThisExpr) interface{} {
 	value, err := i.lookupVariable(expr.Keyword, expr)
 	if err != nil {
-		runtimeErr := runtimeError{error: err, Line: expr.Keyword.Line}
-		i.RuntimeError = runtimeErr
-
+		i.error(expr.Keyword, e.CodeUndefinedVariable, "undefined variable 'this'")
 	}
 	return value
 }
 
 // statement visitors
-func (i *Interpreter) VisitExpressionStmt(stmt abs.ExpressionStmt) interface{} {
+func (i *Interpreter) VisitExpressionStmt(stmt *abs.ExpressionStmt) interface{} {
 	i.evaluate(stmt.Expression)
 	return nil
 }
 
-func (i *Interpreter) VisitFunctionStmt(stmt abs.FunctionStmt) interface{} {
-	function := loxFunction{Declaration: stmt, Closure: i.Environment, isInitialiser: false}
+func (i *Interpreter) VisitFunctionStmt(stmt *abs.FunctionStmt) interface{} {
+	function := i.newFunction(stmt, i.Environment, false, "")
 	i.Environment.Define(stmt.Name.Lexeme, function)
 	return nil
 }
 
-func (i *Interpreter) VisitPrintStmt(stmt abs.PrintStmt) interface{} {
+func (i *Interpreter) VisitPrintStmt(stmt *abs.PrintStmt) interface{} {
 	value := i.evaluate(stmt.Expression)
-	fmt.Fprint(i.stdOut, i.stringify(value))
+	fmt.Fprintln(i.stdOut, i.stringify(value))
 	return nil
 }
 
@@ -266,7 +400,7 @@ type returnValue struct {
 	Value interface{}
 }
 
-func (i *Interpreter) VisitReturnStmt(stmt abs.ReturnStmt) interface{} {
+func (i *Interpreter) VisitReturnStmt(stmt *abs.ReturnStmt) interface{} {
 	var value interface{} = nil
 	if stmt.Value != nil {
 		value = i.evaluate(stmt.Value)
@@ -275,7 +409,7 @@ func (i *Interpreter) VisitReturnStmt(stmt abs.ReturnStmt) interface{} {
 	panic(returnValue)
 }
 
-func (i *Interpreter) VisitVarStmt(stmt abs.VarStmt) interface{} {
+func (i *Interpreter) VisitVarStmt(stmt *abs.VarStmt) interface{} {
 	var value interface{}
 	if stmt.Initialiser != nil {
 		value = i.evaluate(stmt.Initialiser)
@@ -284,12 +418,12 @@ func (i *Interpreter) VisitVarStmt(stmt abs.VarStmt) interface{} {
 	return nil
 }
 
-func (i *Interpreter) VisitBlockStmt(stmt abs.BlockStmt) interface{} {
-	i.executeBlock(stmt.Statements, env.NewEnvironment(i.Environment))
+func (i *Interpreter) VisitBlockStmt(stmt *abs.BlockStmt) interface{} {
+	i.executeBlock(stmt.Statements, i.newEnvironment(i.Environment))
 	return nil
 }
 
-func (i *Interpreter) VisitIfStmt(stmt abs.IfStmt) interface{} {
+func (i *Interpreter) VisitIfStmt(stmt *abs.IfStmt) interface{} {
 	if i.isTruthy(i.evaluate(stmt.Condition)) {
 		i.execute(stmt.ThenBranch)
 	} else if stmt.ElseBranch != nil {
@@ -298,48 +432,100 @@ func (i *Interpreter) VisitIfStmt(stmt abs.IfStmt) interface{} {
 	return nil
 }
 
-func (i *Interpreter) VisitWhileStmt(stmt abs.WhileStmt) interface{} {
+// break and continue panic with these, to be recovered by the loop with the label or the innermost loop when the
+// label is empty
+type breakLoop struct {
+	label string
+}
+type continueLoop struct {
+	label string
+}
+
+func (i *Interpreter) VisitBreakStmt(stmt *abs.BreakStmt) interface{} {
+	panic(breakLoop{label: labelName(stmt.Label)})
+}
+
+func (i *Interpreter) VisitContinueStmt(stmt *abs.ContinueStmt) interface{} {
+	panic(continueLoop{label: labelName(stmt.Label)})
+}
+
+func labelName(label *t.Token) string {
+	if label == nil {
+		return ""
+	}
+	return label.Lexeme
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt *abs.WhileStmt) interface{} {
 	for i.isTruthy(i.evaluate(stmt.Condition)) {
-		i.execute(stmt.Body)
+		if broke := i.executeLoopBody(stmt.Body, labelName(stmt.Label)); broke {
+			break
+		}
+		if stmt.Increment != nil {
+			i.evaluate(stmt.Increment)
+		}
 	}
 	return nil
 }
 
-func (i *Interpreter) VisitClassStmt(stmt abs.ClassStmt) interface{} {
+// runs one iteration of a loop body, returning whether it ended with a break. a continue ends the iteration early.
+// a break or continue naming another label is passed on to the enclosing loops
+func (i *Interpreter) executeLoopBody(body abs.Stmt, label string) (broke bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			switch err := err.(type) {
+			case breakLoop:
+				if err.label != "" && err.label != label {
+					panic(err)
+				}
+				broke = true
+			case continueLoop:
+				if err.label != "" && err.label != label {
+					panic(err)
+				}
+				broke = false
+			default:
+				panic(err)
+			}
+		}
+	}()
+	i.execute(body)
+	return false
+}
+
+func (i *Interpreter) VisitClassStmt(stmt *abs.ClassStmt) interface{} {
 	var superclass *loxClass = nil
 	if stmt.Superclass != nil {
 		superclassInterface := i.evaluate(stmt.Superclass)
-		superclassAssert, ok := superclassInterface.(loxClass)
+		superclassAssert, ok := superclassInterface.(*loxClass)
 		if !ok {
-			err := runtimeError{error: errors.New("superclass must be a class"), Line: stmt.Name.Line}
-			i.RuntimeError = err
-			return nil
+			i.error(stmt.Superclass.Name, e.CodeSuperclassNotClass, "superclass must be a class")
 		}
-		superclass = &superclassAssert
+		superclass = superclassAssert
 	}
 
-	i.Environment.Define(stmt.Name.Lexeme, nil)
+	enclosing := i.Environment
+	enclosing.Define(stmt.Name.Lexeme, nil)
 
 	if stmt.Superclass != nil {
-		i.Environment = env.NewEnvironment(i.Environment)
+		//a runtime error while making the methods must not leave the super environment in place
+		defer func() {
+			i.Environment = enclosing
+		}()
+		i.Environment = i.newEnvironment(enclosing)
 		i.Environment.Define("super", superclass)
 	}
 
-	methods := make(map[string]loxFunction)
+	methods := make(map[string]*loxFunction)
 	for index := 0; index < len(stmt.Methods); index++ {
 		method := stmt.Methods[index]
 		isInit := method.Name.Lexeme == "init"
-		function := loxFunction{Declaration: method, Closure: i.Environment, isInitialiser: isInit}
+		function := i.newFunction(method, i.Environment, isInit, stmt.Name.Lexeme)
 		methods[method.Name.Lexeme] = function
 	}
 
-	class := loxClass{Name: stmt.Name.Lexeme, SuperClass: superclass, methods: methods}
-
-	if superclass != nil {
-		i.Environment = i.Environment.Enclosing
-	}
-
-	i.Environment.Assign(stmt.Name, class)
+	class := &loxClass{Name: stmt.Name.Lexeme, SuperClass: superclass, methods: methods}
+	enclosing.Assign(stmt.Name, class)
 	return nil
 }
 
@@ -365,6 +551,11 @@ func (i *Interpreter) stringify(value interface{}) string {
 	return fmt.Sprint(value)
 }
 
+// returns a value as print would show it
+func (i *Interpreter) Stringify(value interface{}) string {
+	return i.stringify(value)
+}
+
 func (i *Interpreter) lookupVariable(name t.Token, expr abs.Expr) (interface{}, error) {
 	distance, ok := i.Locals[expr]
 	if ok {
@@ -374,7 +565,26 @@ func (i *Interpreter) lookupVariable(name t.Token, expr abs.Expr) (interface{},
 	}
 }
 
+// calls callee after checking it can be called with that many arguements. the call gets a frame on the call stack
+// for as long as it runs, reported against callSite
+func (i *Interpreter) call(callee interface{}, arguements []interface{}, callSite t.Token) interface{} {
+	function, callable := callee.(loxCallable)
+	if !callable {
+		i.error(callSite, e.CodeNotCallable, "can only call functions and classes")
+	}
+	if least, most := function.arity(); len(arguements) < least || (most != noLimit && len(arguements) > most) {
+		i.error(callSite, e.CodeArity, arityMessage(least, most, len(arguements)))
+	}
+	i.checkCallDepth(callSite)
+	i.frames = append(i.frames, i.frameFor(function, callSite))
+	defer func() {
+		i.frames = i.frames[:len(i.frames)-1]
+	}()
+	return function.call(i, arguements)
+}
+
 func (i *Interpreter) execute(stmt abs.Stmt) {
+	i.checkLimits()
 	stmt.Accept(i)
 }
 
@@ -382,6 +592,16 @@ func (i *Interpreter) Resolve(expr abs.Expr, depth int) {
 	i.Locals[expr] = depth
 }
 
+// evaluates an expression in the given environment rather than the current one
+func (i *Interpreter) evaluateIn(expr abs.Expr, environment *env.Environment) interface{} {
+	previous := i.Environment
+	defer func() {
+		i.Environment = previous
+	}()
+	i.Environment = environment
+	return i.evaluate(expr)
+}
+
 func (i *Interpreter) executeBlock(statements []abs.Stmt, environment *env.Environment) {
 	previous := i.Environment
 	defer func() {
@@ -400,11 +620,8 @@ func (i *Interpreter) checkNumberOperand(operator t.Token, operand interface{})
 	if _, ok := operand.(float64); ok {
 		return true
 	}
-	err := runtimeError{error: fmt.Errorf("operand must be a number"), Line: operator.Line}
-
-	i.RuntimeError = err
-	e.RuntimeError(i.stdErr, err.Error(), err.Line)
-	panic(err)
+	i.error(operator, e.CodeOperandType, "operand must be a number")
+	return false
 }
 
 func (i *Interpreter) checkNumberOperands(operator t.Token, left interface{}, right interface{}) bool {
@@ -413,10 +630,25 @@ func (i *Interpreter) checkNumberOperands(operator t.Token, left interface{}, ri
 	if leftIsFloat && rightIsFloat {
 		return true
 	}
-	err := runtimeError{error: fmt.Errorf("operands must be numbers"), Line: operator.Line}
-	e.RuntimeError(i.stdErr, err.Error(), err.Line)
-	i.RuntimeError = err
+	i.error(operator, e.CodeOperandType, "operands must be numbers")
+	return false
+
+}
 
-	panic(err)
+// stops the program by throwing a runtimeError reported against the token. Interpret recovers it and returns it
+// as a diagnostic so each runtime error is reported exactly once
+func (i *Interpreter) error(token t.Token, code string, msg string) {
+	i.errorAt(token.Span, code, errors.New(msg))
+}
+
+// stops the program with a runtime error reported against the span, which is left empty for errors such as
+// timeouts that are not about one place in the source
+func (i *Interpreter) errorAt(span t.Span, code string, err error) {
+	i.lastErrorTrace = i.StackTrace()
+	panic(runtimeError{error: err, Code: code, Span: span, Trace: i.lastErrorTrace})
+}
 
+// raises the error a native function returned at the call to the native
+func (i *Interpreter) nativeError(err error) {
+	i.errorAt(i.frames[len(i.frames)-1].CallSite, e.CodeNative, err)
 }
@@ -0,0 +1,81 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// the call depth used when Limits.MaxCallDepth is zero. it is well inside what the Go stack holds, so runaway
+// recursion is a Lox runtime error rather than a fatal Go stack overflow
+const DefaultMaxCallDepth = 10000
+
+// Limits bound how much work a program may do before it is stopped with a runtime error. a zero field means no
+// limit, apart from MaxCallDepth. the Max limits apply to each run, a call to Interpret, Evaluate or Call from Go,
+// rather than adding up across runs. the quotas on what the program creates are for the life of the interpreter
+type Limits struct {
+	//statements executed, counting each pass through a loop body
+	MaxStatements int
+	//Lox calls in progress at once, past which a call is a stack overflow. zero means DefaultMaxCallDepth
+	MaxCallDepth int
+	//wall-clock time a run may take
+	Timeout time.Duration
+
+	InstanceQuota    int
+	ClosureQuota     int
+	EnvironmentQuota int
+	StringByteQuota  int
+	ElementQuota     int
+}
+
+// sets up the context and counters for a run. runs started while another is in progress, such as a native calling
+// back into Lox, share the outer run's context and counters. the returned func ends the run
+func (i *Interpreter) begin(ctx context.Context) func() {
+	if i.ctx != nil {
+		return func() {}
+	}
+	cancel := context.CancelFunc(func() {})
+	if i.Limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, i.Limits.Timeout)
+	}
+	i.ctx = ctx
+	i.statements = 0
+	//an outermost run always starts at the top level, whatever an earlier run stopped in
+	i.Environment = i.Globals
+	return func() {
+		cancel()
+		i.ctx = nil
+	}
+}
+
+// called before each statement is executed to stop the program once it is cancelled, out of time or over its
+// statement budget
+func (i *Interpreter) checkLimits() {
+	i.statements++
+	if i.Limits.MaxStatements > 0 && i.statements > i.Limits.MaxStatements {
+		i.errorAt(t.Span{}, e.CodeStatementLimit, fmt.Errorf("exceeded the limit of %v statements", i.Limits.MaxStatements))
+	}
+	select {
+	case <-i.ctx.Done():
+		if errors.Is(i.ctx.Err(), context.DeadlineExceeded) {
+			i.errorAt(t.Span{}, e.CodeTimeout, errors.New("execution timed out"))
+		}
+		i.errorAt(t.Span{}, e.CodeCancelled, errors.New("execution cancelled"))
+	default:
+	}
+}
+
+// called before a call pushes its frame
+func (i *Interpreter) checkCallDepth(callSite t.Token) {
+	maxCallDepth := i.Limits.MaxCallDepth
+	if maxCallDepth == 0 {
+		maxCallDepth = DefaultMaxCallDepth
+	}
+	if len(i.frames) >= maxCallDepth {
+		i.error(callSite, e.CodeStackOverflow, "Stack overflow")
+	}
+}
@@ -0,0 +1,156 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// loxList is the value of a list literal. like instances, lists are shared by reference so a list changed through
+// one variable is changed for every variable holding it
+type loxList struct {
+	Elements []interface{}
+}
+
+func (l *loxList) String() string {
+	return l.stringify(map[interface{}]bool{})
+}
+
+// seen holds the lists and maps being printed so one that contains itself prints as [...] rather than forever
+func (l *loxList) stringify(seen map[interface{}]bool) string {
+	if seen[l] {
+		return "[...]"
+	}
+	seen[l] = true
+	defer delete(seen, l)
+	elements := make([]string, len(l.Elements))
+	for index := 0; index < len(l.Elements); index++ {
+		elements[index] = stringifyElement(l.Elements[index], seen)
+	}
+	return fmt.Sprintf("[%v]", strings.Join(elements, ", "))
+}
+
+// prints a value held in a list or map
+func stringifyElement(value interface{}, seen map[interface{}]bool) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case *loxList:
+		return value.stringify(seen)
+	case *loxMap:
+		return value.stringify(seen)
+	}
+	return fmt.Sprint(value)
+}
+
+// returns the list method name bound to the list
+func (l *loxList) get(name t.Token) (interface{}, error) {
+	if method, ok := listMethods[name.Lexeme]; ok {
+		return method.bind(l), nil
+	}
+	return nil, fmt.Errorf("undefined property '%v'", name.Lexeme)
+}
+
+// returns the whole number a value holds, if it holds one
+func wholeNumber(value interface{}) (int, bool) {
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+	return int(number), true
+}
+
+// returns the position in a list of the given length an index refers to, which has to be a whole number from 0 up
+// to the last element, or one past it when allowEnd is set
+func listPosition(index interface{}, length int, allowEnd bool) (int, error) {
+	position, ok := wholeNumber(index)
+	if !ok {
+		return 0, fmt.Errorf("list index must be a whole number")
+	}
+	if position < 0 || position > length || (position == length && !allowEnd) {
+		return 0, fmt.Errorf("list index %v out of range for length %v", position, length)
+	}
+	return position, nil
+}
+
+// checks an index used in xs[i], stopping the program with a runtime error at the bracket if it is not a whole
+// number within the list
+func (i *Interpreter) checkIndex(bracket t.Token, list *loxList, index interface{}) int {
+	if _, ok := wholeNumber(index); !ok {
+		i.error(bracket, e.CodeIndexType, "list index must be a whole number")
+	}
+	position, err := listPosition(index, len(list.Elements), false)
+	if err != nil {
+		i.error(bracket, e.CodeIndexOutOfRange, err.Error())
+	}
+	return position
+}
+
+func listMethod(name string, arity int, function func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error)) *NativeFunction {
+	return &NativeFunction{
+		Name:      name,
+		Arity:     arity,
+		className: "list",
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			return function(interpreter, arguements[0].(*loxList), arguements[1:])
+		},
+	}
+}
+
+// the methods of every list, which get the list as their first arguement
+var listMethods = map[string]*NativeFunction{
+	"len": listMethod("len", 0, func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error) {
+		return float64(len(list.Elements)), nil
+	}),
+	"push": listMethod("push", 1, func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error) {
+		interpreter.grow(interpreter.allocationSite(), 1)
+		list.Elements = append(list.Elements, arguements[0])
+		return nil, nil
+	}),
+	"pop": listMethod("pop", 0, func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error) {
+		if len(list.Elements) == 0 {
+			return nil, fmt.Errorf("can't pop from an empty list")
+		}
+		last := list.Elements[len(list.Elements)-1]
+		list.Elements = list.Elements[:len(list.Elements)-1]
+		return last, nil
+	}),
+	"slice": listMethod("slice", 2, func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error) {
+		//the ends of a slice may be one past the last element
+		start, err := listPosition(arguements[0], len(list.Elements), true)
+		if err != nil {
+			return nil, err
+		}
+		end, err := listPosition(arguements[1], len(list.Elements), true)
+		if err != nil {
+			return nil, err
+		}
+		if start > end {
+			return nil, fmt.Errorf("slice start %v is after its end %v", start, end)
+		}
+		return interpreter.newList(interpreter.allocationSite(), append([]interface{}(nil), list.Elements[start:end]...)), nil
+	}),
+	"insert": listMethod("insert", 2, func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error) {
+		position, err := listPosition(arguements[0], len(list.Elements), true)
+		if err != nil {
+			return nil, err
+		}
+		interpreter.grow(interpreter.allocationSite(), 1)
+		list.Elements = append(list.Elements, nil)
+		copy(list.Elements[position+1:], list.Elements[position:])
+		list.Elements[position] = arguements[1]
+		return nil, nil
+	}),
+	"remove": listMethod("remove", 1, func(interpreter *Interpreter, list *loxList, arguements []Value) (Value, error) {
+		position, err := listPosition(arguements[0], len(list.Elements), false)
+		if err != nil {
+			return nil, err
+		}
+		removed := list.Elements[position]
+		list.Elements = append(list.Elements[:position], list.Elements[position+1:]...)
+		return removed, nil
+	}),
+}
@@ -0,0 +1,136 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// loxMap is the value of a map literal. any value can be a key: strings, numbers, booleans and nil by value,
+// everything else by identity. entries keep the order they were added in, so keys() and printing give the same
+// order every run. maps are shared by reference like lists
+type loxMap struct {
+	order   []interface{}
+	entries map[interface{}]interface{}
+}
+
+func newLoxMap() *loxMap {
+	return &loxMap{entries: make(map[interface{}]interface{})}
+}
+
+// NaN is not equal to itself, so a NaN key could be added but never found again
+func checkKey(key interface{}) error {
+	if number, ok := key.(float64); ok && math.IsNaN(number) {
+		return fmt.Errorf("NaN can't be used as a map key")
+	}
+	return nil
+}
+
+func (m *loxMap) lookup(key interface{}) (interface{}, bool, error) {
+	if err := checkKey(key); err != nil {
+		return nil, false, err
+	}
+	value, ok := m.entries[key]
+	return value, ok, nil
+}
+
+func (m *loxMap) set(key interface{}, value interface{}) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if _, ok := m.entries[key]; !ok {
+		m.order = append(m.order, key)
+	}
+	m.entries[key] = value
+	return nil
+}
+
+// sets a key in the map, counting a new entry against the quota
+func (i *Interpreter) setKey(span t.Span, m *loxMap, key interface{}, value interface{}) {
+	if err := checkKey(key); err != nil {
+		i.errorAt(span, e.CodeInvalidKey, err)
+	}
+	if _, ok := m.entries[key]; !ok {
+		i.grow(span, 1)
+	}
+	m.set(key, value)
+}
+
+// removes the key and reports whether it was there
+func (m *loxMap) delete(key interface{}) bool {
+	if _, ok := m.entries[key]; !ok {
+		return false
+	}
+	delete(m.entries, key)
+	for index := 0; index < len(m.order); index++ {
+		if m.order[index] == key {
+			m.order = append(m.order[:index], m.order[index+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (m *loxMap) String() string {
+	return m.stringify(map[interface{}]bool{})
+}
+
+func (m *loxMap) stringify(seen map[interface{}]bool) string {
+	if seen[m] {
+		return "{...}"
+	}
+	seen[m] = true
+	defer delete(seen, m)
+	entries := make([]string, len(m.order))
+	for index := 0; index < len(m.order); index++ {
+		key := m.order[index]
+		entries[index] = fmt.Sprintf("%v: %v", stringifyElement(key, seen), stringifyElement(m.entries[key], seen))
+	}
+	return fmt.Sprintf("{%v}", strings.Join(entries, ", "))
+}
+
+// returns the map method name bound to the map
+func (m *loxMap) get(name t.Token) (interface{}, error) {
+	if method, ok := mapMethods[name.Lexeme]; ok {
+		return method.bind(m), nil
+	}
+	return nil, fmt.Errorf("undefined property '%v'", name.Lexeme)
+}
+
+func mapMethod(name string, arity int, function func(interpreter *Interpreter, m *loxMap, arguements []Value) (Value, error)) *NativeFunction {
+	return &NativeFunction{
+		Name:      name,
+		Arity:     arity,
+		className: "map",
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			return function(interpreter, arguements[0].(*loxMap), arguements[1:])
+		},
+	}
+}
+
+// the methods of every map, which get the map as their first arguement
+var mapMethods = map[string]*NativeFunction{
+	"len": mapMethod("len", 0, func(interpreter *Interpreter, m *loxMap, arguements []Value) (Value, error) {
+		return float64(len(m.order)), nil
+	}),
+	"keys": mapMethod("keys", 0, func(interpreter *Interpreter, m *loxMap, arguements []Value) (Value, error) {
+		return interpreter.newList(interpreter.allocationSite(), append([]interface{}(nil), m.order...)), nil
+	}),
+	"values": mapMethod("values", 0, func(interpreter *Interpreter, m *loxMap, arguements []Value) (Value, error) {
+		values := make([]interface{}, len(m.order))
+		for index := 0; index < len(m.order); index++ {
+			values[index] = m.entries[m.order[index]]
+		}
+		return interpreter.newList(interpreter.allocationSite(), values), nil
+	}),
+	"has": mapMethod("has", 1, func(interpreter *Interpreter, m *loxMap, arguements []Value) (Value, error) {
+		_, ok, err := m.lookup(arguements[0])
+		return ok, err
+	}),
+	"delete": mapMethod("delete", 1, func(interpreter *Interpreter, m *loxMap, arguements []Value) (Value, error) {
+		return m.delete(arguements[0]), nil
+	}),
+}
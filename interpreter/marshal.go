@@ -0,0 +1,642 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// converting between Go and Lox values. numbers become float64, strings and bools stay as they are and Go functions
+// become natives that convert their arguements and results. a struct becomes an instance with a field for each
+// exported field and a method for each exported method. the instance keeps the struct it came from, writing its
+// fields back to the struct before a method is called or the instance is converted back, and reading them again
+// after the method returns. slices and arrays are copied into lists and maps into Lox maps, with their keys sorted
+// so they come out in the same order every run
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ToValue converts a Go value to the Lox value that stands in for it. Lox values are returned unchanged
+func (i *Interpreter) ToValue(goValue interface{}) (Value, error) {
+	return i.toValue(reflect.ValueOf(goValue))
+}
+
+// FromValue converts a Lox value into the Go value that out points to, in the way json.Unmarshal fills its
+// arguement
+func (i *Interpreter) FromValue(value Value, out interface{}) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("cannot convert into %T, it is not a pointer", out)
+	}
+	converted, err := i.fromValue(value, target.Type().Elem())
+	if err != nil {
+		return err
+	}
+	target.Elem().Set(converted)
+	return nil
+}
+
+// WrapFunc makes a native function from any Go function whose results are nothing, a value, an error or a value
+// and an error. a returned error becomes a runtime error
+func (i *Interpreter) WrapFunc(name string, fn interface{}) (*NativeFunction, error) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return nil, fmt.Errorf("cannot wrap %T, it is not a function", fn)
+	}
+	return i.wrapFunc(name, value)
+}
+
+func isLoxValue(value interface{}) bool {
+	switch value.(type) {
+	case *loxFunction, *loxClass, *loxInstance, *loxList, *loxMap, *NativeFunction:
+		return true
+	}
+	return false
+}
+
+// identifies a Go struct pointer, map or slice within one conversion, so one reached twice or one that refers back
+// to itself becomes a single Lox value rather than being converted until the stack runs out
+type goReference struct {
+	goType  reflect.Type
+	pointer uintptr
+	length  int
+}
+
+func referenceTo(value reflect.Value) goReference {
+	reference := goReference{goType: value.Type(), pointer: value.Pointer()}
+	if value.Kind() == reflect.Slice {
+		reference.length = value.Len()
+	}
+	return reference
+}
+
+func (i *Interpreter) toValue(value reflect.Value) (Value, error) {
+	return i.convert(value, map[goReference]Value{})
+}
+
+// converts a Go value, reusing the Lox values already made for the references in seen
+func (i *Interpreter) convert(value reflect.Value, seen map[goReference]Value) (Value, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if value.CanInterface() && isLoxValue(value.Interface()) {
+		return value.Interface(), nil
+	}
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Interface:
+		return i.convert(value.Elem(), seen)
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil, nil
+		}
+		if value.Elem().Kind() == reflect.Struct {
+			if instance, ok := seen[referenceTo(value)]; ok {
+				return instance, nil
+			}
+			return i.goInstance(value, seen)
+		}
+		return i.convert(value.Elem(), seen)
+	case reflect.Struct:
+		//copied so the instance has a struct of its own to write its fields to
+		pointer := reflect.New(value.Type())
+		pointer.Elem().Set(value)
+		return i.goInstance(pointer, seen)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil, nil
+		}
+		//empty slices can share an address, so only slices with elements are looked up
+		shared := value.Kind() == reflect.Slice && value.Len() > 0
+		if list, ok := seen[referenceTo(value)]; shared && ok {
+			return list, nil
+		}
+		if err := reserve(&i.allocations.Elements, 1+value.Len(), i.Limits.ElementQuota, "elements"); err != nil {
+			return nil, err
+		}
+		list := &loxList{Elements: make([]interface{}, value.Len())}
+		if shared {
+			seen[referenceTo(value)] = list
+		}
+		for index := 0; index < value.Len(); index++ {
+			element, err := i.convert(value.Index(index), seen)
+			if err != nil {
+				return nil, fmt.Errorf("element %v: %v", index, err)
+			}
+			list.Elements[index] = element
+		}
+		return list, nil
+	case reflect.Map:
+		if value.IsNil() {
+			return nil, nil
+		}
+		if m, ok := seen[referenceTo(value)]; ok {
+			return m, nil
+		}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return keyLess(keys[a], keys[b])
+		})
+		if err := reserve(&i.allocations.Elements, 1+len(keys), i.Limits.ElementQuota, "elements"); err != nil {
+			return nil, err
+		}
+		m := newLoxMap()
+		seen[referenceTo(value)] = m
+		for index := 0; index < len(keys); index++ {
+			key, err := i.convert(keys[index], seen)
+			if err != nil {
+				return nil, fmt.Errorf("key: %v", err)
+			}
+			element, err := i.convert(value.MapIndex(keys[index]), seen)
+			if err != nil {
+				return nil, fmt.Errorf("value for %v: %v", i.stringify(key), err)
+			}
+			if err := m.set(key, element); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case reflect.Func:
+		if value.IsNil() {
+			return nil, nil
+		}
+		return i.wrapFunc("native", value)
+	}
+	return nil, fmt.Errorf("cannot use %v as a Lox value", value.Type())
+}
+
+// identifies a Lox list, map or instance converted to a Go type within one conversion, the other way round from
+// goReference
+type loxReference struct {
+	value  Value
+	target reflect.Type
+}
+
+func (i *Interpreter) fromValue(value Value, target reflect.Type) (reflect.Value, error) {
+	return i.convertBack(value, target, map[loxReference]reflect.Value{})
+}
+
+// converts a Lox value to a Go value of the target type, reusing the Go values already made for the lists, maps
+// and instances in seen
+func (i *Interpreter) convertBack(value Value, target reflect.Type, seen map[loxReference]reflect.Value) (reflect.Value, error) {
+	if converted, ok := seen[loxReference{value: value, target: target}]; ok {
+		return converted, nil
+	}
+	if target.Kind() == reflect.Interface {
+		if value == nil {
+			return reflect.Zero(target), nil
+		}
+		if instance, ok := value.(*loxInstance); ok && instance.goValue.IsValid() && instance.goValue.Type().Implements(target) {
+			err := i.writeFields(instance, seen)
+			return instance.goValue, err
+		}
+		converted := reflect.ValueOf(value)
+		if !converted.Type().Implements(target) {
+			return reflect.Value{}, mismatch(value, target)
+		}
+		return converted, nil
+	}
+	if instance, ok := value.(*loxInstance); ok && instance.goValue.IsValid() {
+		if instance.goValue.Type() == target {
+			err := i.writeFields(instance, seen)
+			return instance.goValue, err
+		}
+		if instance.goValue.Kind() == reflect.Pointer && instance.goValue.Type().Elem() == target {
+			err := i.writeFields(instance, seen)
+			return instance.goValue.Elem(), err
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		if boolean, ok := value.(bool); ok {
+			return reflect.ValueOf(boolean).Convert(target), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number, ok := value.(float64); ok {
+			converted := reflect.New(target).Elem()
+			if number != math.Trunc(number) || converted.OverflowInt(int64(number)) {
+				return reflect.Value{}, fmt.Errorf("cannot use %v as %v", number, target)
+			}
+			converted.SetInt(int64(number))
+			return converted, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if number, ok := value.(float64); ok {
+			converted := reflect.New(target).Elem()
+			if number != math.Trunc(number) || number < 0 || converted.OverflowUint(uint64(number)) {
+				return reflect.Value{}, fmt.Errorf("cannot use %v as %v", number, target)
+			}
+			converted.SetUint(uint64(number))
+			return converted, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if number, ok := value.(float64); ok {
+			return reflect.ValueOf(number).Convert(target), nil
+		}
+	case reflect.String:
+		if str, ok := value.(string); ok {
+			return reflect.ValueOf(str).Convert(target), nil
+		}
+	case reflect.Pointer:
+		if value == nil {
+			return reflect.Zero(target), nil
+		}
+		//the pointer is recorded before the value it points to is converted so values leading back here share it
+		pointer := reflect.New(target.Elem())
+		if isReference(value) {
+			seen[loxReference{value: value, target: target}] = pointer
+		}
+		elem, err := i.convertBack(value, target.Elem(), seen)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		pointer.Elem().Set(elem)
+		return pointer, nil
+	case reflect.Struct:
+		if instance, ok := value.(*loxInstance); ok {
+			return i.structFromFields(instance, target, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		if value == nil && target.Kind() == reflect.Slice {
+			return reflect.Zero(target), nil
+		}
+		if list, ok := value.(*loxList); ok {
+			return i.sliceFromList(list, target, seen)
+		}
+	case reflect.Map:
+		if value == nil {
+			return reflect.Zero(target), nil
+		}
+		if m, ok := value.(*loxMap); ok {
+			return i.goMapFromMap(m, target, seen)
+		}
+	case reflect.Func:
+		if value == nil {
+			return reflect.Zero(target), nil
+		}
+		if _, ok := value.(loxCallable); ok {
+			return i.goFunc(value, target), nil
+		}
+	}
+	return reflect.Value{}, mismatch(value, target)
+}
+
+// reports whether a Lox value is shared by reference, so converting it twice has to give the same Go value
+func isReference(value Value) bool {
+	switch value.(type) {
+	case *loxInstance, *loxList, *loxMap:
+		return true
+	}
+	return false
+}
+
+func mismatch(value Value, target reflect.Type) error {
+	return fmt.Errorf("cannot use %v as %v", typeName(value), target)
+}
+
+// the name of a Lox value's type for error messages
+func typeName(value Value) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "a bool"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case *loxInstance:
+		return fmt.Sprintf("a %v instance", value.Class.Name)
+	case *loxClass:
+		return "a class"
+	case *loxList:
+		return "a list"
+	case *loxMap:
+		return "a map"
+	}
+	return "a function"
+}
+
+// builds a struct of the target type from the fields of an instance. fields the instance does not have are left as
+// their zero value
+func (i *Interpreter) structFromFields(instance *loxInstance, target reflect.Type, seen map[loxReference]reflect.Value) (reflect.Value, error) {
+	converted := reflect.New(target).Elem()
+	for index := 0; index < target.NumField(); index++ {
+		field := target.Field(index)
+		value, ok := instance.Fields[field.Name]
+		if !field.IsExported() || !ok {
+			continue
+		}
+		fieldValue, err := i.convertBack(value, field.Type, seen)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field %v: %v", field.Name, err)
+		}
+		converted.Field(index).Set(fieldValue)
+	}
+	return converted, nil
+}
+
+// copies the elements of a list into a new slice or array of the target type
+func (i *Interpreter) sliceFromList(list *loxList, target reflect.Type, seen map[loxReference]reflect.Value) (reflect.Value, error) {
+	var converted reflect.Value
+	if target.Kind() == reflect.Array {
+		if len(list.Elements) != target.Len() {
+			return reflect.Value{}, fmt.Errorf("cannot use %v elements as %v", len(list.Elements), target)
+		}
+		converted = reflect.New(target).Elem()
+	} else {
+		converted = reflect.MakeSlice(target, len(list.Elements), len(list.Elements))
+		seen[loxReference{value: list, target: target}] = converted
+	}
+	for index := 0; index < len(list.Elements); index++ {
+		element, err := i.convertBack(list.Elements[index], target.Elem(), seen)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %v: %v", index, err)
+		}
+		converted.Index(index).Set(element)
+	}
+	return converted, nil
+}
+
+// copies the entries of a Lox map into a new Go map of the target type
+func (i *Interpreter) goMapFromMap(m *loxMap, target reflect.Type, seen map[loxReference]reflect.Value) (reflect.Value, error) {
+	converted := reflect.MakeMapWithSize(target, len(m.order))
+	seen[loxReference{value: m, target: target}] = converted
+	for index := 0; index < len(m.order); index++ {
+		key, err := i.convertBack(m.order[index], target.Key(), seen)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key: %v", err)
+		}
+		value, err := i.convertBack(m.entries[m.order[index]], target.Elem(), seen)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("value for %v: %v", i.stringify(m.order[index]), err)
+		}
+		converted.SetMapIndex(key, value)
+	}
+	return converted, nil
+}
+
+// makes an instance standing in for a Go struct pointer. it is added to seen before its fields are read so fields
+// that lead back to the struct get the same instance
+func (i *Interpreter) goInstance(goValue reflect.Value, seen map[goReference]Value) (*loxInstance, error) {
+	instance := &loxInstance{Class: i.goClass(goValue.Type()), Fields: make(map[string]interface{}), goValue: goValue}
+	seen[referenceTo(goValue)] = instance
+	if err := i.readFields(instance, seen); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// returns the class for a pointer to a Go struct type, making it the first time the type is seen
+func (i *Interpreter) goClass(goType reflect.Type) *loxClass {
+	if class, ok := i.goClasses[goType]; ok {
+		return class
+	}
+	if i.goClasses == nil {
+		i.goClasses = make(map[reflect.Type]*loxClass)
+	}
+	class := &loxClass{Name: goType.Elem().Name(), natives: make(map[string]*NativeFunction), goType: goType}
+	if class.Name == "" {
+		class.Name = "struct"
+	}
+	i.goClasses[goType] = class
+	for index := 0; index < goType.NumMethod(); index++ {
+		i.defineGoMethod(class, goType.Method(index))
+	}
+	return class
+}
+
+// the exported fields of a struct are copied between the struct and the instance standing in for it. readFields
+// copies them to the instance, writeFields back to the struct. seen is nil when the fields are read again after a
+// method call, so a field pointing back at the struct gets the instance itself
+func (i *Interpreter) readFields(instance *loxInstance, seen map[goReference]Value) error {
+	if instance.goValue.Kind() != reflect.Pointer {
+		return nil
+	}
+	if seen == nil {
+		seen = map[goReference]Value{referenceTo(instance.goValue): instance}
+	}
+	structValue := instance.goValue.Elem()
+	for index := 0; index < structValue.NumField(); index++ {
+		field := structValue.Type().Field(index)
+		if !field.IsExported() {
+			continue
+		}
+		value, err := i.convert(structValue.Field(index), seen)
+		if err != nil {
+			return fmt.Errorf("field %v: %v", field.Name, err)
+		}
+		instance.Fields[field.Name] = value
+	}
+	return nil
+}
+
+// an instance is written once per conversion, so instances that lead back to each other stop there
+func (i *Interpreter) writeFields(instance *loxInstance, seen map[loxReference]reflect.Value) error {
+	if instance.goValue.Kind() != reflect.Pointer {
+		return nil
+	}
+	if seen == nil {
+		seen = map[loxReference]reflect.Value{}
+	}
+	reference := loxReference{value: instance, target: instance.goValue.Type()}
+	if _, ok := seen[reference]; ok {
+		return nil
+	}
+	seen[reference] = instance.goValue
+	structValue := instance.goValue.Elem()
+	for index := 0; index < structValue.NumField(); index++ {
+		field := structValue.Type().Field(index)
+		value, ok := instance.Fields[field.Name]
+		if !field.IsExported() || !ok {
+			continue
+		}
+		fieldValue, err := i.convertBack(value, field.Type, seen)
+		if err != nil {
+			return fmt.Errorf("field %v: %v", field.Name, err)
+		}
+		structValue.Field(index).Set(fieldValue)
+	}
+	return nil
+}
+
+func (i *Interpreter) defineGoMethod(class *loxClass, method reflect.Method) {
+	methodType := method.Func.Type()
+	if !validResults(methodType) {
+		return
+	}
+	native := &NativeFunction{Name: method.Name, Arity: methodType.NumIn() - 1, Variadic: methodType.IsVariadic(), className: class.Name}
+	if native.Variadic {
+		native.Arity--
+	}
+	native.Function = func(interpreter *Interpreter, arguements []Value) (Value, error) {
+		instance := arguements[0].(*loxInstance)
+		//an instance made by calling the class from Lox has no struct of its own yet
+		if !instance.goValue.IsValid() {
+			instance.goValue = reflect.New(class.goType.Elem())
+		}
+		if err := interpreter.writeFields(instance, nil); err != nil {
+			return nil, err
+		}
+		in, err := interpreter.goArguements(methodType, arguements[1:], 1)
+		if err != nil {
+			return nil, err
+		}
+		result, err := interpreter.goResults(method.Func.Call(append([]reflect.Value{instance.goValue}, in...)))
+		if readErr := interpreter.readFields(instance, nil); readErr != nil && err == nil {
+			err = readErr
+		}
+		return result, err
+	}
+	class.natives[method.Name] = native
+}
+
+func (i *Interpreter) wrapFunc(name string, fn reflect.Value) (*NativeFunction, error) {
+	fnType := fn.Type()
+	if !validResults(fnType) {
+		return nil, fmt.Errorf("cannot wrap %v, it returns more than a value and an error", fnType)
+	}
+	native := &NativeFunction{Name: name, Arity: fnType.NumIn(), Variadic: fnType.IsVariadic()}
+	if native.Variadic {
+		native.Arity--
+	}
+	native.Function = func(interpreter *Interpreter, arguements []Value) (Value, error) {
+		in, err := interpreter.goArguements(fnType, arguements, 0)
+		if err != nil {
+			return nil, err
+		}
+		return interpreter.goResults(fn.Call(in))
+	}
+	return native, nil
+}
+
+// a Go function can be called from Lox if it returns nothing, a value, an error or a value then an error
+func validResults(fnType reflect.Type) bool {
+	switch fnType.NumOut() {
+	case 0, 1:
+		return true
+	case 2:
+		return fnType.Out(1) == errorType
+	}
+	return false
+}
+
+// converts arguements for the parameters of a Go function from first onwards
+func (i *Interpreter) goArguements(fnType reflect.Type, arguements []Value, first int) ([]reflect.Value, error) {
+	in := make([]reflect.Value, len(arguements))
+	for index := 0; index < len(arguements); index++ {
+		parameter := first + index
+		var parameterType reflect.Type
+		if fnType.IsVariadic() && parameter >= fnType.NumIn()-1 {
+			parameterType = fnType.In(fnType.NumIn() - 1).Elem()
+		} else {
+			parameterType = fnType.In(parameter)
+		}
+		value, err := i.fromValue(arguements[index], parameterType)
+		if err != nil {
+			return nil, fmt.Errorf("arguement %v: %v", index+1, err)
+		}
+		in[index] = value
+	}
+	return in, nil
+}
+
+func (i *Interpreter) goResults(out []reflect.Value) (Value, error) {
+	if len(out) > 0 && out[len(out)-1].Type() == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return nil, err
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return i.toValue(out[0])
+}
+
+// makes a Go function of the target type that calls a Lox callable. called while Lox code is running, such as from
+// inside a native, a runtime error in the callable carries on unwinding the Lox program. called from anywhere else
+// the error is returned if the function returns an error and panics otherwise
+func (i *Interpreter) goFunc(callee Value, target reflect.Type) reflect.Value {
+	return reflect.MakeFunc(target, func(in []reflect.Value) []reflect.Value {
+		result, err := i.callFromGo(callee, in)
+		out := make([]reflect.Value, target.NumOut())
+		for index := 0; index < len(out); index++ {
+			out[index] = reflect.Zero(target.Out(index))
+		}
+		if len(out) > 0 && target.Out(len(out)-1) == errorType {
+			if err != nil {
+				out[len(out)-1] = reflect.ValueOf(&err).Elem()
+				return out
+			}
+		} else if err != nil {
+			panic(err)
+		}
+		if len(out) > 0 && target.Out(0) != errorType {
+			converted, err := i.fromValue(result, target.Out(0))
+			if err != nil {
+				if target.Out(len(out)-1) != errorType {
+					panic(err)
+				}
+				out[len(out)-1] = reflect.ValueOf(&err).Elem()
+				return out
+			}
+			out[0] = converted
+		}
+		return out
+	})
+}
+
+func (i *Interpreter) callFromGo(callee Value, in []reflect.Value) (Value, error) {
+	arguements := make([]Value, len(in))
+	for index := 0; index < len(in); index++ {
+		value, err := i.toValue(in[index])
+		if err != nil {
+			return nil, fmt.Errorf("arguement %v: %v", index+1, err)
+		}
+		arguements[index] = value
+	}
+	name := i.stringify(callee)
+	if len(i.frames) > 0 {
+		callSite := t.Token{TokenType: t.TokenIdentifier, Lexeme: name, Span: i.frames[len(i.frames)-1].CallSite}
+		return i.call(callee, arguements, callSite), nil
+	}
+	result, diagnostics, exit := i.Call(name, callee, arguements)
+	if exit != nil {
+		return nil, exit
+	}
+	if len(diagnostics) > 0 {
+		return nil, errors.New(diagnostics[0].String())
+	}
+	return result, nil
+}
+
+// orders map keys of the same Go type, numbers by value and anything else by how it prints
+func keyLess(a reflect.Value, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Value is a Lox value as the interpreter holds it: nil, bool, float64, string or one of the interpreter's
+// functions, classes and instances
+type Value = interface{}
+
+// NativeFunction is a function written in Go that Lox code can call. Arity is the number of arguements it takes,
+// or the fewest it takes when Variadic is set. an error returned by Function becomes a runtime error at the call,
+// and any other Go value it returns is converted as ToValue converts it. Requires is the capabilities the native needs, calling it without them is a runtime error
+type NativeFunction struct {
+	Name     string
+	Arity    int
+	Variadic bool
+	Requires Capabilities
+	Function func(interpreter *Interpreter, arguements []Value) (Value, error)
+	//name of the class a native method belongs to, empty for functions
+	className string
+}
+
+func (n *NativeFunction) arity() (int, int) {
+	if n.Variadic {
+		return n.Arity, noLimit
+	}
+	return n.Arity, n.Arity
+}
+
+func (n *NativeFunction) call(interpreter *Interpreter, arguements []interface{}) interface{} {
+	if missing := n.Requires &^ interpreter.capabilities; missing != NoCapabilities {
+		interpreter.nativeError(fmt.Errorf("%v needs %v access, which is denied", n.Name, missing))
+	}
+	value, err := n.Function(interpreter, arguements)
+	if exit, ok := err.(*Exit); ok {
+		//unwinds the program to the entry point, which returns it
+		panic(exit)
+	}
+	if err != nil {
+		interpreter.nativeError(err)
+	}
+	//a host callback may return any Go value, such as an int or a slice, which is converted so the program only
+	//sees Lox values
+	if !isLoxValue(value) {
+		converted, err := interpreter.toValue(reflect.ValueOf(value))
+		if err != nil {
+			interpreter.nativeError(fmt.Errorf("result: %v", err))
+		}
+		value = converted
+	}
+	return value
+}
+
+// binds a native method to the instance or list it was looked up on, which is passed to Function ahead of the
+// other arguements
+func (n *NativeFunction) bind(receiver Value) *NativeFunction {
+	return &NativeFunction{
+		Name:      n.Name,
+		Arity:     n.Arity,
+		Variadic:  n.Variadic,
+		Requires:  n.Requires,
+		className: n.className,
+		Function: func(interpreter *Interpreter, arguements []Value) (Value, error) {
+			return n.Function(interpreter, append([]Value{receiver}, arguements...))
+		},
+	}
+}
+
+func (n *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+// DefineNative makes a native function available to Lox code as a global, replacing any global of the same name
+func (i *Interpreter) DefineNative(native *NativeFunction) {
+	i.Globals.Define(native.Name, native)
+}
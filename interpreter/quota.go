@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"fmt"
+
+	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
+	env "github.com/constwhite/golox-interpreter/environment"
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// Allocations counts what an interpreter has created over its lifetime. counts only go up, values the program no
+// longer uses are not taken off
+type Allocations struct {
+	Instances int
+	//functions, methods and methods bound to an instance
+	Closures     int
+	Environments int
+	//bytes in the strings made by concatenation
+	StringBytes int
+	//lists and maps, counting one for each list or map and one for each element or entry it is made with or grows by
+	Elements int
+}
+
+// Allocations returns what the interpreter has created so far
+func (i *Interpreter) Allocations() Allocations {
+	return i.allocations
+}
+
+// adds amount to a count, stopping the program with a runtime error once the count goes over its quota
+func (i *Interpreter) allocate(span t.Span, count *int, amount int, quota int, what string) {
+	if err := reserve(count, amount, quota, what); err != nil {
+		i.errorAt(span, e.CodeQuota, err)
+	}
+}
+
+// adds amount to a count, returning an error rather than stopping the program once the count goes over its quota.
+// it is for allocations made outside a run, such as converting a Go value
+func reserve(count *int, amount int, quota int, what string) error {
+	*count += amount
+	if quota > 0 && *count > quota {
+		return fmt.Errorf("exceeded the quota of %v %v", quota, what)
+	}
+	return nil
+}
+
+// allocations are reported against the call in progress, or nowhere at the top level of the script
+func (i *Interpreter) allocationSite() t.Span {
+	if len(i.frames) == 0 {
+		return t.Span{}
+	}
+	return i.frames[len(i.frames)-1].CallSite
+}
+
+func (i *Interpreter) newEnvironment(enclosing *env.Environment) *env.Environment {
+	i.allocate(i.allocationSite(), &i.allocations.Environments, 1, i.Limits.EnvironmentQuota, "environments")
+	return env.NewEnvironment(enclosing)
+}
+
+func (i *Interpreter) newFunction(declaration *abs.FunctionStmt, closure *env.Environment, isInitialiser bool, className string) *loxFunction {
+	i.allocate(i.allocationSite(), &i.allocations.Closures, 1, i.Limits.ClosureQuota, "closures")
+	return &loxFunction{Declaration: declaration, Closure: closure, isInitialiser: isInitialiser, className: className}
+}
+
+func (i *Interpreter) newInstance(class *loxClass) *loxInstance {
+	i.allocate(i.allocationSite(), &i.allocations.Instances, 1, i.Limits.InstanceQuota, "instances")
+	return &loxInstance{Class: class, Fields: make(map[string]interface{})}
+}
+
+// concatenates two strings, counting the bytes of the result against the quota
+func (i *Interpreter) concatenate(operator t.Token, left string, right string) string {
+	i.allocate(operator.Span, &i.allocations.StringBytes, len(left)+len(right), i.Limits.StringByteQuota, "string bytes")
+	return left + right
+}
+
+// makes a list holding elements, counting the list and its elements against the quota
+func (i *Interpreter) newList(span t.Span, elements []interface{}) *loxList {
+	i.grow(span, 1+len(elements))
+	return &loxList{Elements: elements}
+}
+
+// makes an empty map, counting it against the quota
+func (i *Interpreter) newMap(span t.Span) *loxMap {
+	i.grow(span, 1)
+	return newLoxMap()
+}
+
+// counts the elements a list or the entries a map grows by against the quota
+func (i *Interpreter) grow(span t.Span, amount int) {
+	i.allocate(span, &i.allocations.Elements, amount, i.Limits.ElementQuota, "elements")
+}
@@ -1,48 +1,60 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/constwhite/golox-interpreter/errorHandler"
 	"github.com/constwhite/golox-interpreter/interpreter"
-	"github.com/constwhite/golox-interpreter/parser"
-	"github.com/constwhite/golox-interpreter/resolver"
-	"github.com/constwhite/golox-interpreter/scanner"
+	"github.com/constwhite/golox-interpreter/repl"
 )
 
-var hadError bool
-var hadRuntimeError bool
+var diagnosticsFormat = flag.String("diagnostics", "text", "format errors are written to stderr in, text or json")
 
 func main() {
 	// golox filepath.lox. get filepath from args. if empty run repl, if args[1] not empty run file from path. if >1 throw error
-	args := os.Args
-	if len(args) > 2 {
-		log.Println("Usage: golox [script]")
+	flag.Usage = func() {
+		log.Println("Usage: golox [-diagnostics text|json] [script]")
+	}
+	flag.Parse()
+	if *diagnosticsFormat != "text" && *diagnosticsFormat != "json" {
+		flag.Usage()
 		os.Exit(64)
-	} else if len(args) == 1 {
+	}
+	args := flag.Args()
+	if len(args) > 1 {
+		flag.Usage()
+		os.Exit(64)
+	} else if len(args) == 0 {
 		runPrompt()
 
-	} else if len(args) == 2 {
-		runFile(args[1])
+	} else if len(args) == 1 {
+		runFile(args[0])
 	}
 
 }
 
+// creates a session that writes its diagnostics in the format picked on the command line
+func newSession() *repl.Session {
+	session := repl.NewSession(os.Stdout, os.Stderr)
+	if *diagnosticsFormat == "json" {
+		session.Renderer = errorHandler.NewJSONRenderer(os.Stderr)
+	}
+	return session
+}
+
 func runPrompt() {
-	//reads from command line returning tokens
-	input := bufio.NewScanner(os.Stdin)
-	// opens a loop. as long as input is not null, input.Scan() returns true.
-	for input.Scan() {
-		fmt.Print("> ")
-		// imput.Text() returns most recently generated token from scanner
-		line := input.Text()
-		run(line)
-		hadError = false
+	//one session for the whole prompt so state declared on one line is visible on the next
+	session := newSession()
+	err := session.Prompt(os.Stdin)
+	var exit *interpreter.Exit
+	if errors.As(err, &exit) {
+		os.Exit(exit.Code)
 	}
-	// when input.Scan() returns false break loop. if err returned from input.Err() print the error to console. if nil the input has ended successfully
-	if err := input.Err(); err != nil {
+	if err != nil {
 		fmt.Printf("read input error: %v", err)
 	} else {
 		println("End of input, exiting...")
@@ -57,7 +69,10 @@ func runFile(path string) {
 	}
 	//converts to string. allowing to use the byte array as text
 	fileString := string(file)
-	run(fileString)
+	hadError, hadRuntimeError, exit := newSession().Run(fileString)
+	if exit != nil {
+		os.Exit(exit.Code)
+	}
 	if hadError {
 		os.Exit(65)
 	}
@@ -67,28 +82,3 @@ func runFile(path string) {
 	}
 
 }
-
-func run(source string) {
-	// init new scanner. NOT bufio.NewScanner, this is the scanner we are going to build not yet impleneted
-
-	scanner := scanner.NewScanner(source, os.Stderr)
-	tokens := scanner.ScanTokens()
-
-	parser := parser.NewParser(tokens, os.Stderr)
-	statements, HadError := parser.Parse()
-
-	if HadError {
-		return
-	}
-	interpreter := interpreter.NewInterpreter(os.Stderr, os.Stdout)
-	resolver := resolver.NewResolver(interpreter)
-	HadError = resolver.ResolveStatements(statements)
-	if HadError {
-		return
-	}
-	hadRuntimeError = interpreter.Interpret(statements)
-	if hadRuntimeError {
-		return
-	}
-
-}
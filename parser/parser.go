@@ -2,7 +2,6 @@ package parser
 
 import (
 	"fmt"
-	"io"
 
 	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
 	e "github.com/constwhite/golox-interpreter/errorHandler"
@@ -10,10 +9,11 @@ import (
 )
 
 type Parser struct {
-	stdErr       io.Writer
 	current      int
 	sourceTokens []t.Token
-	HadError     bool
+	diagnostics  []e.Diagnostic
+	//set when an error is reported at the EOF token, meaning the source ended part way through a statement
+	UnexpectedEOF bool
 }
 
 type parseError struct {
@@ -24,17 +24,37 @@ func (pe *parseError) Error() string {
 	return pe.msg
 }
 
-func NewParser(sourceTokens []t.Token, stdErr io.Writer) *Parser {
-	return &Parser{sourceTokens: sourceTokens, stdErr: stdErr}
+func NewParser(sourceTokens []t.Token) *Parser {
+	return &Parser{sourceTokens: sourceTokens}
 }
 
-func (p *Parser) Parse() ([]abs.Stmt, bool) {
+// parses the tokens as a list of declarations. after an error the parser synchronises on the next statement so
+// every syntax error is returned rather than only the first
+func (p *Parser) Parse() ([]abs.Stmt, []e.Diagnostic) {
 	var statements []abs.Stmt
 	for !p.isAtEnd() {
 		statements = append(statements, p.declaration())
 	}
 
-	return statements, p.HadError
+	return statements, p.diagnostics
+}
+
+// parses the tokens as a single expression with nothing following it
+func (p *Parser) ParseExpression() (expr abs.Expr, diagnostics []e.Diagnostic) {
+	defer func() {
+		if err := recover(); err != nil {
+			if _, ok := err.(parseError); ok {
+				diagnostics = p.diagnostics
+				return
+			}
+			panic(err)
+		}
+	}()
+	expr = p.expression()
+	if !p.isAtEnd() {
+		p.error(p.peek(), e.CodeSyntax, "expect end of expression")
+	}
+	return expr, p.diagnostics
 }
 
 // grammar functions
@@ -45,7 +65,6 @@ func (p *Parser) declaration() abs.Stmt {
 		//i dislike this error handling as it is not very go but unsure how to handle it otherwise
 		if err := recover(); err != nil {
 			if _, ok := err.(parseError); ok {
-				p.HadError = true
 				p.synchronise()
 			} else {
 				panic(err)
@@ -56,7 +75,9 @@ func (p *Parser) declaration() abs.Stmt {
 	if p.match(t.TokenClass) {
 		return p.classDeclaration()
 	}
-	if p.match(t.TokenFun) {
+	//fun followed by '(' is an anonymous function used as an expression statement
+	if p.check(t.TokenFun) && !p.checkNext(t.TokenLeftParen) {
+		p.advance()
 		return p.function("function")
 	}
 	if p.match(t.TokenVar) {
@@ -73,12 +94,12 @@ func (p *Parser) classDeclaration() abs.Stmt {
 		superclass = &abs.VariableExpr{Name: p.previous()}
 	}
 	p.consume(t.TokenLeftBrace, "expect '{' before class body")
-	var methods []abs.FunctionStmt = nil
+	var methods []*abs.FunctionStmt = nil
 	for !p.check(t.TokenRightBrace) && !p.isAtEnd() {
 		methods = append(methods, p.function("method"))
 	}
 	p.consume(t.TokenRightBrace, "expect '}' after class body")
-	return abs.ClassStmt{Name: name, Superclass: superclass, Methods: methods}
+	return &abs.ClassStmt{Name: name, Superclass: superclass, Methods: methods}
 }
 
 func (p *Parser) varDeclaration() abs.Stmt {
@@ -88,20 +109,29 @@ func (p *Parser) varDeclaration() abs.Stmt {
 		initialiser = p.expression()
 	}
 	p.consume(t.TokenSemiColon, "expect ';' after variable declaration")
-	return abs.VarStmt{Name: name, Initialiser: initialiser}
+	return &abs.VarStmt{Name: name, Initialiser: initialiser}
 }
 
-func (p *Parser) whileStatement() abs.Stmt {
+func (p *Parser) whileStatement(label *t.Token) abs.Stmt {
 	p.consume(t.TokenLeftParen, "expect '(' after 'while'")
 	condition := p.expression()
 	p.consume(t.TokenRightParen, "expect ')' after condition")
 	body := p.statement()
-	return abs.WhileStmt{Condition: condition, Body: body}
+	return &abs.WhileStmt{Condition: condition, Body: body, Label: label}
 }
 
 func (p *Parser) statement() abs.Stmt {
+	if p.match(t.TokenBreak) {
+		return p.breakStatement()
+	}
+	if p.match(t.TokenContinue) {
+		return p.continueStatement()
+	}
+	if p.check(t.TokenIdentifier) && p.checkNext(t.TokenColon) {
+		return p.labeledStatement()
+	}
 	if p.match(t.TokenFor) {
-		return p.forStatement()
+		return p.forStatement(nil)
 	}
 	if p.match(t.TokenIf) {
 		return p.ifStatement()
@@ -113,16 +143,30 @@ func (p *Parser) statement() abs.Stmt {
 		return p.returnStatement()
 	}
 	if p.match(t.TokenWhile) {
-		return p.whileStatement()
+		return p.whileStatement(nil)
 	}
 	if p.match(t.TokenLeftBrace) {
-		return abs.BlockStmt{Statements: p.blockStatement()}
+		return &abs.BlockStmt{Statements: p.blockStatement()}
 	}
 
 	return p.expressionStatement()
 }
 
-func (p *Parser) forStatement() abs.Stmt {
+// parses a loop with a label in front of it, such as outer: for (...)
+func (p *Parser) labeledStatement() abs.Stmt {
+	label := p.advance()
+	p.advance()
+	if p.match(t.TokenFor) {
+		return p.forStatement(&label)
+	}
+	if p.match(t.TokenWhile) {
+		return p.whileStatement(&label)
+	}
+	p.error(p.peek(), e.CodeSyntax, "expect a loop after label")
+	return nil
+}
+
+func (p *Parser) forStatement(label *t.Token) abs.Stmt {
 	p.consume(t.TokenLeftParen, "expect '(' after 'for'")
 	var initialiser abs.Stmt
 	if p.match(t.TokenSemiColon) {
@@ -144,17 +188,14 @@ func (p *Parser) forStatement() abs.Stmt {
 	p.consume(t.TokenRightParen, "expect ')' after for clauses")
 	body := p.statement()
 
-	if increment != nil {
-		body = abs.BlockStmt{
-			Statements: []abs.Stmt{body, abs.ExpressionStmt{Expression: increment}},
-		}
-	}
 	if condition == nil {
-		condition = abs.LiteralExpr{Value: true}
-		body = abs.WhileStmt{Condition: condition, Body: body}
+		condition = &abs.LiteralExpr{Value: true}
 	}
+	//the increment is kept apart from the body so a continue in the body still runs it
+	//the label goes on the loop itself rather than the block holding the initialiser
+	body = &abs.WhileStmt{Condition: condition, Body: body, Increment: increment, Label: label}
 	if initialiser != nil {
-		body = abs.BlockStmt{Statements: []abs.Stmt{initialiser, body}}
+		body = &abs.BlockStmt{Statements: []abs.Stmt{initialiser, body}}
 	}
 
 	return body
@@ -169,19 +210,42 @@ func (p *Parser) ifStatement() abs.Stmt {
 	if p.match(t.TokenElse) {
 		elseBranch = p.statement()
 	}
-	return abs.IfStmt{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}
+	return &abs.IfStmt{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}
 
 }
 
 func (p *Parser) printStatement() abs.Stmt {
 	value := p.expression()
 	p.consume(t.TokenSemiColon, "expect ';' after value")
-	return abs.PrintStmt{Expression: value}
+	return &abs.PrintStmt{Expression: value}
 }
 func (p *Parser) expressionStatement() abs.Stmt {
 	expression := p.expression()
 	p.consume(t.TokenSemiColon, "expect ';' after value")
-	return abs.ExpressionStmt{Expression: expression}
+	return &abs.ExpressionStmt{Expression: expression}
+}
+
+func (p *Parser) breakStatement() abs.Stmt {
+	keyword := p.previous()
+	label := p.loopLabel()
+	p.consume(t.TokenSemiColon, "expect ';' after 'break'")
+	return &abs.BreakStmt{Keyword: keyword, Label: label}
+}
+
+func (p *Parser) continueStatement() abs.Stmt {
+	keyword := p.previous()
+	label := p.loopLabel()
+	p.consume(t.TokenSemiColon, "expect ';' after 'continue'")
+	return &abs.ContinueStmt{Keyword: keyword, Label: label}
+}
+
+// parses the optional label after break or continue
+func (p *Parser) loopLabel() *t.Token {
+	if !p.match(t.TokenIdentifier) {
+		return nil
+	}
+	label := p.previous()
+	return &label
 }
 
 func (p *Parser) returnStatement() abs.Stmt {
@@ -191,28 +255,101 @@ func (p *Parser) returnStatement() abs.Stmt {
 		value = p.expression()
 	}
 	p.consume(t.TokenSemiColon, "expect ';' after return value")
-	return abs.ReturnStmt{Keyword: keyword, Value: value}
+	return &abs.ReturnStmt{Keyword: keyword, Value: value}
 }
 
-func (p *Parser) function(kind string) abs.FunctionStmt {
+func (p *Parser) function(kind string) *abs.FunctionStmt {
 	name := p.consume(t.TokenIdentifier, fmt.Sprintf("expect %v name", kind))
 	p.consume(t.TokenLeftParen, fmt.Sprintf("expect '(' after %v name", kind))
-	var params []t.Token = nil
+	function := p.parameters()
+	function.Name = name
+	p.consume(t.TokenLeftBrace, fmt.Sprintf("expect '{' before %v body", kind))
+	function.Body = p.blockStatement()
+	return function
+}
+
+// parses a parameter list up to and including its ')', returning a declaration with only the parameters filled in.
+// parameters with a default have to come after those without and a rest parameter has to come last
+func (p *Parser) parameters() *abs.FunctionStmt {
+	function := &abs.FunctionStmt{}
 	if !p.check(t.TokenRightParen) {
 		for {
-			if len(params) >= 255 {
-				p.error(p.peek(), "number of parameters can not exceed 255")
+			if len(function.Params) >= 255 {
+				p.error(p.peek(), e.CodeTooManyParameters, "number of parameters can not exceed 255")
+			}
+			if p.match(t.TokenEllipsis) {
+				rest := p.consume(t.TokenIdentifier, "expect rest parameter name")
+				function.Rest = &rest
+				if p.check(t.TokenComma) {
+					p.error(p.peek(), e.CodeSyntax, "rest parameter must be the last parameter")
+				}
+				break
+			}
+			param := p.consume(t.TokenIdentifier, "expect parameter name")
+			var value abs.Expr = nil
+			if p.match(t.TokenEqual) {
+				value = p.expression()
+			} else if len(function.Defaults) > 0 && function.Defaults[len(function.Defaults)-1] != nil {
+				p.error(param, e.CodeSyntax, "parameter without a default can not follow one with a default")
 			}
-			params = append(params, p.consume(t.TokenIdentifier, "expect parameter name"))
+			function.Params = append(function.Params, param)
+			function.Defaults = append(function.Defaults, value)
 			if !p.match(t.TokenComma) {
 				break
 			}
 		}
 	}
 	p.consume(t.TokenRightParen, "expect ')' after parameters")
-	p.consume(t.TokenLeftBrace, fmt.Sprintf("expect '{' before %v body", kind))
-	body := p.blockStatement()
-	return abs.FunctionStmt{Name: name, Params: params, Body: body}
+	return function
+}
+
+// parses an anonymous function after its 'fun'
+func (p *Parser) functionExpression() abs.Expr {
+	keyword := p.previous()
+	p.consume(t.TokenLeftParen, "expect '(' after 'fun'")
+	function := p.parameters()
+	p.consume(t.TokenLeftBrace, "expect '{' before function body")
+	function.Body = p.blockStatement()
+	return &abs.FunctionExpr{Keyword: keyword, Declaration: anonymous(keyword, function)}
+}
+
+// parses an arrow function after its '('. the body is either a block or an expression whose value is returned
+func (p *Parser) arrowFunction() abs.Expr {
+	function := p.parameters()
+	arrow := p.consume(t.TokenArrow, "expect '=>' after parameters")
+	if p.match(t.TokenLeftBrace) {
+		function.Body = p.blockStatement()
+	} else {
+		function.Body = []abs.Stmt{&abs.ReturnStmt{Keyword: arrow, Value: p.expression()}}
+	}
+	return &abs.FunctionExpr{Keyword: arrow, Declaration: anonymous(arrow, function)}
+}
+
+// reports whether the '(' just matched starts the parameters of an arrow function, by looking past its matching ')'
+// for '=>'
+func (p *Parser) isArrowFunction() bool {
+	depth := 1
+	for index := p.current; index < len(p.sourceTokens); index++ {
+		switch p.sourceTokens[index].TokenType {
+		case t.TokenLeftParen:
+			depth++
+		case t.TokenRightParen:
+			depth--
+			if depth == 0 {
+				return index+1 < len(p.sourceTokens) && p.sourceTokens[index+1].TokenType == t.TokenArrow
+			}
+		case t.TokenEOF:
+			return false
+		}
+	}
+	return false
+}
+
+// names an anonymous function by a token with an empty lexeme at the keyword it was written with
+func anonymous(keyword t.Token, function *abs.FunctionStmt) *abs.FunctionStmt {
+	function.Name = keyword
+	function.Name.Lexeme = ""
+	return function
 }
 
 func (p *Parser) blockStatement() []abs.Stmt {
@@ -232,7 +369,7 @@ func (p *Parser) equality() abs.Expr {
 	for p.match(t.TokenBangEqual, t.TokenEqualEqual) {
 		operator := p.previous()
 		right := p.comparison()
-		expr = abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
+		expr = &abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
 	}
 
 	return expr
@@ -243,7 +380,7 @@ func (p *Parser) comparison() abs.Expr {
 	for p.match(t.TokenGreater, t.TokenGreaterEqual, t.TokenLesser, t.TokenLesserEqual) {
 		operator := p.previous()
 		right := p.term()
-		expr = abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
+		expr = &abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
 	}
 
 	return expr
@@ -254,7 +391,7 @@ func (p *Parser) term() abs.Expr {
 	for p.match(t.TokenMinus, t.TokenPlus) {
 		operator := p.previous()
 		right := p.factor()
-		expr = abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
+		expr = &abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
 	}
 
 	return expr
@@ -266,7 +403,7 @@ func (p *Parser) factor() abs.Expr {
 	for p.match(t.TokenSlash, t.TokenStar) {
 		operator := p.previous()
 		right := p.unary()
-		expr = abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
+		expr = &abs.BinaryExpr{Left: expr, Operator: operator, Right: right}
 	}
 
 	return expr
@@ -276,7 +413,7 @@ func (p *Parser) unary() abs.Expr {
 	if p.match(t.TokenBang, t.TokenMinus) {
 		operator := p.previous()
 		right := p.unary()
-		return abs.UnaryExpr{Operator: operator, Right: right}
+		return &abs.UnaryExpr{Operator: operator, Right: right}
 	}
 
 	return p.call()
@@ -289,7 +426,12 @@ func (p *Parser) call() abs.Expr {
 			expr = p.finishCall(expr)
 		} else if p.match(t.TokenDot) {
 			name := p.consume(t.TokenIdentifier, "expect property name after '.'")
-			expr = abs.GetExpr{Object: expr, Name: name}
+			expr = &abs.GetExpr{Object: expr, Name: name}
+		} else if p.match(t.TokenLeftBracket) {
+			bracket := p.previous()
+			index := p.expression()
+			p.consume(t.TokenRightBracket, "expect ']' after index")
+			expr = &abs.IndexExpr{Object: expr, Bracket: bracket, Index: index}
 		} else {
 			break
 		}
@@ -299,19 +441,28 @@ func (p *Parser) call() abs.Expr {
 
 func (p *Parser) finishCall(callee abs.Expr) abs.Expr {
 	var arguements []abs.Expr = nil
+	var named []abs.NamedArguement = nil
 	if !p.check(t.TokenRightParen) {
 		for {
-			if len(arguements) >= 255 {
-				p.error(p.peek(), "functions can not accept more than 255 arguements")
+			if len(arguements)+len(named) >= 255 {
+				p.error(p.peek(), e.CodeTooManyArguements, "functions can not accept more than 255 arguements")
+			}
+			if p.check(t.TokenIdentifier) && p.checkNext(t.TokenColon) {
+				name := p.advance()
+				p.advance()
+				named = append(named, abs.NamedArguement{Name: name, Value: p.expression()})
+			} else if len(named) > 0 {
+				p.error(p.peek(), e.CodeSyntax, "positional arguements can not follow named arguements")
+			} else {
+				arguements = append(arguements, p.expression())
 			}
-			arguements = append(arguements, p.expression())
 			if !p.match(t.TokenComma) {
 				break
 			}
 		}
 	}
 	paren := p.consume(t.TokenRightParen, "expect ')' after arguements")
-	return abs.CallExpr{Callee: callee, Paren: paren, Arguements: arguements}
+	return &abs.CallExpr{Callee: callee, Paren: paren, Arguements: arguements, NamedArguements: named}
 }
 
 func (p *Parser) assignment() abs.Expr {
@@ -319,15 +470,17 @@ func (p *Parser) assignment() abs.Expr {
 	if p.match(t.TokenEqual) {
 		equals := p.previous()
 		value := p.assignment()
-		if exprVariable, ok := expr.(abs.VariableExpr); ok {
+		if exprVariable, ok := expr.(*abs.VariableExpr); ok {
 			name := exprVariable.Name
-			return abs.AssignExpr{Name: name, Value: value}
-		} else if exprGet, ok := expr.(abs.GetExpr); ok {
+			return &abs.AssignExpr{Name: name, Value: value}
+		} else if exprGet, ok := expr.(*abs.GetExpr); ok {
 			name := exprGet.Name
 			object := exprGet.Object
-			return abs.SetExpr{Object: object, Name: name, Value: value}
+			return &abs.SetExpr{Object: object, Name: name, Value: value}
+		} else if exprIndex, ok := expr.(*abs.IndexExpr); ok {
+			return &abs.SetIndexExpr{Object: exprIndex.Object, Bracket: exprIndex.Bracket, Index: exprIndex.Index, Value: value}
 		} else {
-			p.error(equals, "invalid assignment target")
+			p.error(equals, e.CodeInvalidAssignmentTarget, "invalid assignment target")
 		}
 
 	}
@@ -340,7 +493,7 @@ func (p *Parser) or() abs.Expr {
 	for p.match(t.TokenOr) {
 		operator := p.previous()
 		right := p.and()
-		expr = abs.LogicalExpr{Left: expr, Operator: operator, Right: right}
+		expr = &abs.LogicalExpr{Left: expr, Operator: operator, Right: right}
 	}
 	return expr
 }
@@ -351,7 +504,7 @@ func (p *Parser) and() abs.Expr {
 	for p.match(t.TokenAnd) {
 		operator := p.previous()
 		right := p.equality()
-		expr = abs.LogicalExpr{Left: expr, Operator: operator, Right: right}
+		expr = &abs.LogicalExpr{Left: expr, Operator: operator, Right: right}
 	}
 	return expr
 }
@@ -359,42 +512,88 @@ func (p *Parser) and() abs.Expr {
 // parses primary expressions
 func (p *Parser) primary() abs.Expr {
 	if p.match(t.TokenFalse) {
-		return abs.LiteralExpr{Value: false}
+		return &abs.LiteralExpr{Value: false}
 	}
 	if p.match(t.TokenTrue) {
-		return abs.LiteralExpr{Value: true}
+		return &abs.LiteralExpr{Value: true}
 	}
 	if p.match(t.TokenNil) {
-		return abs.LiteralExpr{Value: nil}
+		return &abs.LiteralExpr{Value: nil}
 	}
 
 	if p.match(t.TokenNumber, t.TokenString) {
-		return abs.LiteralExpr{Value: p.previous().Literal}
+		return &abs.LiteralExpr{Value: p.previous().Literal}
 	}
 	if p.match(t.TokenSuper) {
 		keyword := p.previous()
 		p.consume(t.TokenDot, "expect '.' after 'super'")
 		method := p.consume(t.TokenIdentifier, "expect superclass method name")
-		return abs.SuperExpr{Keyword: keyword, Method: method}
+		return &abs.SuperExpr{Keyword: keyword, Method: method}
 	}
 
 	if p.match(t.TokenThis) {
-		return abs.ThisExpr{Keyword: p.previous()}
+		return &abs.ThisExpr{Keyword: p.previous()}
 	}
 
 	if p.match(t.TokenIdentifier) {
-		return abs.VariableExpr{Name: p.previous()}
+		return &abs.VariableExpr{Name: p.previous()}
+	}
+
+	if p.match(t.TokenFun) {
+		return p.functionExpression()
 	}
 
 	if p.match(t.TokenLeftParen) {
+		if p.isArrowFunction() {
+			return p.arrowFunction()
+		}
 		expr := p.expression()
 		p.consume(t.TokenRightParen, "expect ')' after expression")
-		return abs.GroupingExpr{Expression: expr}
+		return &abs.GroupingExpr{Expression: expr}
+	}
+
+	if p.match(t.TokenLeftBracket) {
+		return p.list()
+	}
+
+	if p.match(t.TokenLeftBrace) {
+		return p.mapLiteral()
 	}
-	p.error(p.peek(), "expect expression")
+	p.error(p.peek(), e.CodeSyntax, "expect expression")
 	return nil
 }
 
+// parses the elements of a list literal after its '['. a trailing comma is allowed
+func (p *Parser) list() abs.Expr {
+	bracket := p.previous()
+	var elements []abs.Expr
+	for !p.check(t.TokenRightBracket) {
+		elements = append(elements, p.expression())
+		if !p.match(t.TokenComma) {
+			break
+		}
+	}
+	p.consume(t.TokenRightBracket, "expect ']' after list elements")
+	return &abs.ListExpr{Bracket: bracket, Elements: elements}
+}
+
+// parses the entries of a map literal after its '{'. a trailing comma is allowed. a '{' starting a statement is
+// always a block, so a map literal can't begin an expression statement
+func (p *Parser) mapLiteral() abs.Expr {
+	brace := p.previous()
+	var keys, values []abs.Expr
+	for !p.check(t.TokenRightBrace) {
+		keys = append(keys, p.expression())
+		p.consume(t.TokenColon, "expect ':' after map key")
+		values = append(values, p.expression())
+		if !p.match(t.TokenComma) {
+			break
+		}
+	}
+	p.consume(t.TokenRightBrace, "expect '}' after map entries")
+	return &abs.MapExpr{Brace: brace, Keys: keys, Values: values}
+}
+
 // error handling
 
 // checks if current token is the specified token type. throws error if the token is not expected
@@ -402,46 +601,36 @@ func (p *Parser) consume(tokenType t.TokenType, message string) t.Token {
 	if p.check(tokenType) {
 		return p.advance()
 	}
-	p.error(p.peek(), message)
+	p.error(p.peek(), e.CodeSyntax, message)
 	return t.Token{}
 
 }
 
-// throws a parseError using panic. not a very go way of error handling
-func (p *Parser) error(token t.Token, message string) {
-	var where string
+// records the error against the token then throws a parseError using panic. not a very go way of error handling
+func (p *Parser) error(token t.Token, code string, message string) {
 	if token.TokenType == t.TokenEOF {
-		where = "at end"
-	} else {
-		where = fmt.Sprintf("at '%v'", token.Lexeme)
+		p.UnexpectedEOF = true
 	}
 	err := parseError{msg: message}
-	e.ReportError(p.stdErr, err.Error(), where, token.Line+1)
+	p.diagnostics = append(p.diagnostics, e.NewError(e.PhaseParse, code, err.Error(), token.Span))
 
 	panic(err)
 }
 
 // discards tokens until it finds a statement boundary
 func (p *Parser) synchronise() {
+	p.advance()
 	for !p.isAtEnd() {
 		if p.previous().TokenType == t.TokenSemiColon {
 			return
 		}
 		switch p.peek().TokenType {
-		case t.TokenClass:
-		case t.TokenFun:
-		case t.TokenVar:
-		case t.TokenFor:
-		case t.TokenIf:
-		case t.TokenWhile:
-		case t.TokenPrint:
-		case t.TokenReturn:
+		case t.TokenClass, t.TokenFun, t.TokenVar, t.TokenFor, t.TokenIf, t.TokenWhile, t.TokenPrint, t.TokenReturn,
+			t.TokenBreak, t.TokenContinue:
 			return
-
 		}
+		p.advance()
 	}
-
-	p.advance()
 }
 
 // helper functions
@@ -484,6 +673,14 @@ func (p *Parser) peek() t.Token {
 	return p.sourceTokens[p.current]
 }
 
+// compares the token type of the token after the current one to a given token type
+func (p *Parser) checkNext(tokenType t.TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.sourceTokens[p.current+1].TokenType == tokenType
+}
+
 // looks at the previous token in the source
 func (p *Parser) previous() t.Token {
 	return p.sourceTokens[p.current-1]
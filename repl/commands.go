@@ -0,0 +1,100 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
+	"github.com/constwhite/golox-interpreter/interpreter"
+	"github.com/constwhite/golox-interpreter/parser"
+	"github.com/constwhite/golox-interpreter/scanner"
+)
+
+const helpText = `Enter Lox statements or bare expressions. Unfinished input continues on the next line, an empty line ends it early.
+Commands:
+  :help           show this message
+  :reset          forget every variable, function and class declared so far
+  :load <file>    run a Lox file in the current session
+  :env            list the global variables and their values
+  :ast <expr>     print the syntax tree of an expression
+  :tokens <src>   print the tokens scanned from source
+  :quit           leave the REPL
+`
+
+// runs a meta-command entered at the prompt. returns false when the REPL should stop, along with the exit a loaded
+// file called if that is why
+func (s *Session) command(line string) (bool, *interpreter.Exit) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ":help":
+		fmt.Fprint(s.stdOut, helpText)
+	case ":reset":
+		s.Reset()
+	case ":load":
+		if exit := s.load(arg); exit != nil {
+			return false, exit
+		}
+	case ":env":
+		s.env()
+	case ":ast":
+		s.ast(arg)
+	case ":tokens":
+		s.tokens(arg)
+	case ":quit":
+		return false, nil
+	default:
+		fmt.Fprintf(s.stdErr, "unknown command %v, enter :help for a list of commands\n", name)
+	}
+	return true, nil
+}
+
+func (s *Session) load(path string) *interpreter.Exit {
+	if path == "" {
+		fmt.Fprintln(s.stdErr, "usage: :load <file>")
+		return nil
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(s.stdErr, err)
+		return nil
+	}
+	_, _, exit := s.Run(string(file))
+	return exit
+}
+
+// prints the globals sorted by name
+func (s *Session) env() {
+	values := s.interpreter.Globals.Values
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for index := 0; index < len(names); index++ {
+		name := names[index]
+		fmt.Fprintf(s.stdOut, "%v = %v\n", name, s.interpreter.Stringify(values[name]))
+	}
+}
+
+func (s *Session) ast(source string) {
+	tokens, diagnostics := scanner.NewScanner(source).ScanTokens()
+	if s.report(diagnostics) {
+		return
+	}
+	expr, diagnostics := parser.NewParser(tokens).ParseExpression()
+	if s.report(diagnostics) {
+		return
+	}
+	fmt.Fprintln(s.stdOut, abs.NewPrinter().Print(expr))
+}
+
+func (s *Session) tokens(source string) {
+	tokens, diagnostics := scanner.NewScanner(source).ScanTokens()
+	s.report(diagnostics)
+	for index := 0; index < len(tokens); index++ {
+		fmt.Fprintln(s.stdOut, tokens[index])
+	}
+}
@@ -0,0 +1,247 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// returned by readLine when ctrl-c abandons the line being typed
+var errInterrupt = errors.New("interrupted")
+
+const historyFileName = ".golox_history"
+const historyLimit = 1000
+
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlH     = 8
+	keyCtrlK     = 11
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+type lineReader interface {
+	readLine(prompt string) (string, error)
+	close() error
+}
+
+// picks a line editor when stdIn is a terminal, otherwise lines are read as they are with no editing or history
+func newLineReader(stdIn *os.File, stdOut io.Writer) lineReader {
+	fd := int(stdIn.Fd())
+	if !term.IsTerminal(fd) {
+		return &plainReader{input: bufio.NewScanner(stdIn), stdOut: stdOut}
+	}
+	editor := &lineEditor{fd: fd, input: bufio.NewReader(stdIn), stdOut: stdOut}
+	editor.openHistory()
+	return editor
+}
+
+type plainReader struct {
+	input  *bufio.Scanner
+	stdOut io.Writer
+}
+
+func (r *plainReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.stdOut, prompt)
+	if r.input.Scan() {
+		return r.input.Text(), nil
+	}
+	if err := r.input.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+func (r *plainReader) close() error {
+	return nil
+}
+
+// lineEditor puts the terminal into raw mode while a line is being typed so the cursor can be moved with the arrow
+// keys and previous lines recalled. history is kept in a dotfile in the home directory between sessions
+type lineEditor struct {
+	fd          int
+	input       *bufio.Reader
+	stdOut      io.Writer
+	history     []string
+	historyFile *os.File
+}
+
+func (le *lineEditor) readLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(le.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(le.fd, state)
+	return le.edit(prompt)
+}
+
+// reads keys until the line is entered, editing the line and redrawing it after each one
+func (le *lineEditor) edit(prompt string) (string, error) {
+	var line []rune
+	pos := 0
+	historyIndex := len(le.history)
+	//the line being typed before moving back through the history
+	current := ""
+
+	for {
+		le.redraw(prompt, line, pos)
+		c, _, err := le.input.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(le.stdOut, "\r\n")
+			le.addHistory(string(line))
+			return string(line), nil
+		case keyCtrlC:
+			fmt.Fprint(le.stdOut, "^C\r\n")
+			return "", errInterrupt
+		case keyCtrlD:
+			if len(line) == 0 {
+				fmt.Fprint(le.stdOut, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case keyBackspace, keyCtrlH:
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case keyCtrlA:
+			pos = 0
+		case keyCtrlE:
+			pos = len(line)
+		case keyCtrlK:
+			line = line[:pos]
+		case keyCtrlU:
+			line = line[pos:]
+			pos = 0
+		case keyEscape:
+			switch le.readEscape() {
+			case "A":
+				if historyIndex > 0 {
+					if historyIndex == len(le.history) {
+						current = string(line)
+					}
+					historyIndex--
+					line = []rune(le.history[historyIndex])
+					pos = len(line)
+				}
+			case "B":
+				if historyIndex < len(le.history) {
+					historyIndex++
+					if historyIndex == len(le.history) {
+						line = []rune(current)
+					} else {
+						line = []rune(le.history[historyIndex])
+					}
+					pos = len(line)
+				}
+			case "C":
+				if pos < len(line) {
+					pos++
+				}
+			case "D":
+				if pos > 0 {
+					pos--
+				}
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(line)
+			case "3~":
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if unicode.IsPrint(c) {
+				line = append(line[:pos], append([]rune{c}, line[pos:]...)...)
+				pos++
+			}
+		}
+	}
+}
+
+// reads the rest of an escape sequence after the escape key, returning the final part of it. "A" for the up
+// arrow, "3~" for delete and so on
+func (le *lineEditor) readEscape() string {
+	c, _, err := le.input.ReadRune()
+	if err != nil || (c != '[' && c != 'O') {
+		return ""
+	}
+	var sequence strings.Builder
+	for {
+		c, _, err = le.input.ReadRune()
+		if err != nil {
+			return ""
+		}
+		sequence.WriteRune(c)
+		if !unicode.IsDigit(c) && c != ';' {
+			return sequence.String()
+		}
+	}
+}
+
+// rewrites the prompt and line then moves the cursor back to pos
+func (le *lineEditor) redraw(prompt string, line []rune, pos int) {
+	fmt.Fprintf(le.stdOut, "\r%v%v\x1b[K", prompt, string(line))
+	if back := len(line) - pos; back > 0 {
+		fmt.Fprintf(le.stdOut, "\x1b[%vD", back)
+	}
+}
+
+// loads the history saved by earlier sessions and opens the history file to append new lines to it.
+// history is optional so a missing home directory or unreadable file leaves it empty
+func (le *lineEditor) openHistory() {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(home, historyFileName)
+	if contents, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(contents), "\n") {
+			if line != "" {
+				le.history = append(le.history, line)
+			}
+		}
+		if len(le.history) > historyLimit {
+			le.history = le.history[len(le.history)-historyLimit:]
+		}
+	}
+	le.historyFile, _ = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+}
+
+func (le *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(le.history) > 0 && le.history[len(le.history)-1] == line {
+		return
+	}
+	le.history = append(le.history, line)
+	if le.historyFile != nil {
+		fmt.Fprintln(le.historyFile, line)
+	}
+}
+
+func (le *lineEditor) close() error {
+	if le.historyFile == nil {
+		return nil
+	}
+	return le.historyFile.Close()
+}
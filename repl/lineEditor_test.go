@@ -0,0 +1,88 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		history []string
+		line    string
+		err     error
+	}{
+		{name: "typed", keys: "print 1;\r", line: "print 1;"},
+		{name: "newline ends the line", keys: "abc\n", line: "abc"},
+		{name: "backspace", keys: "abd\x7fc\r", line: "abc"},
+		{name: "backspace at the start", keys: "\x7fab\r", line: "ab"},
+		{name: "ctrl-h", keys: "abd\x08c\r", line: "abc"},
+		{name: "left arrow inserts before the cursor", keys: "ac\x1b[Db\r", line: "abc"},
+		{name: "right arrow", keys: "ac\x1b[D\x1b[Cd\r", line: "acd"},
+		{name: "ctrl-a and ctrl-e", keys: "bc\x01a\x05d\r", line: "abcd"},
+		{name: "home and end", keys: "bc\x1b[Ha\x1b[Fd\r", line: "abcd"},
+		{name: "ctrl-k cuts to the end", keys: "abcd\x1b[D\x1b[D\x0b\r", line: "ab"},
+		{name: "ctrl-u cuts to the start", keys: "abcd\x1b[D\x15\r", line: "d"},
+		{name: "delete", keys: "abc\x01\x1b[3~\r", line: "bc"},
+		{name: "ctrl-d deletes under the cursor", keys: "abc\x01\x04\r", line: "bc"},
+		{name: "ctrl-d on an empty line ends input", keys: "\x04", err: io.EOF},
+		{name: "ctrl-c abandons the line", keys: "abc\x03", err: errInterrupt},
+		{name: "end of input", keys: "abc", err: io.EOF},
+		{name: "control characters are ignored", keys: "a\x02b\r", line: "ab"},
+		{name: "up arrow recalls history", keys: "\x1b[A\r", history: []string{"first", "second"}, line: "second"},
+		{name: "up twice", keys: "\x1b[A\x1b[A\r", history: []string{"first", "second"}, line: "first"},
+		{name: "up past the oldest stays", keys: "\x1b[A\x1b[A\r", history: []string{"only"}, line: "only"},
+		{name: "down returns to the typed line", keys: "new\x1b[A\x1b[B\r", history: []string{"old"}, line: "new"},
+		{name: "recalled lines can be edited", keys: "\x1b[A!\r", history: []string{"old"}, line: "old!"},
+		{name: "unknown escape is ignored", keys: "a\x1b[Zb\r", line: "ab"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			editor := &lineEditor{input: bufio.NewReader(strings.NewReader(test.keys)), stdOut: &bytes.Buffer{}, history: test.history}
+			line, err := editor.edit("> ")
+			if !errors.Is(err, test.err) {
+				t.Fatalf("err = %v, want %v", err, test.err)
+			}
+			if line != test.line {
+				t.Errorf("line = %q, want %q", line, test.line)
+			}
+		})
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	editor := &lineEditor{input: bufio.NewReader(strings.NewReader("one\rone\r  \rtwo\r\x1b[A\x1b[A\r")), stdOut: &bytes.Buffer{}}
+	for index := 0; index < 4; index++ {
+		if _, err := editor.edit("> "); err != nil {
+			t.Fatal(err)
+		}
+	}
+	//repeated and blank lines are not added
+	if strings.Join(editor.history, ",") != "one,two" {
+		t.Errorf("history = %q, want [one two]", editor.history)
+	}
+	line, err := editor.edit("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "one" {
+		t.Errorf("line = %q, want %q", line, "one")
+	}
+}
+
+func TestLineEditorRedraw(t *testing.T) {
+	var stdOut bytes.Buffer
+	editor := &lineEditor{input: bufio.NewReader(strings.NewReader("ab\x1b[D\r")), stdOut: &stdOut}
+	if _, err := editor.edit("> "); err != nil {
+		t.Fatal(err)
+	}
+	want := "\r> \x1b[K" + "\r> a\x1b[K" + "\r> ab\x1b[K" + "\r> ab\x1b[K\x1b[1D" + "\r\n"
+	if stdOut.String() != want {
+		t.Errorf("output = %q, want %q", stdOut.String(), want)
+	}
+}
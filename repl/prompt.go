@@ -0,0 +1,56 @@
+package repl
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Prompt reads input from stdIn and runs it until the input ends or :quit is entered. lines are collected until
+// they make up a complete input, and a line starting with ':' at the start of an input is run as a meta-command.
+// when the program calls exit the prompt stops and returns the *interpreter.Exit, after restoring the terminal and
+// closing the history file
+func (s *Session) Prompt(stdIn *os.File) error {
+	reader := newLineReader(stdIn, s.stdOut)
+	defer reader.close()
+
+	source := ""
+	for {
+		prompt := "> "
+		if source != "" {
+			prompt = "... "
+		}
+		line, err := reader.readLine(prompt)
+		if errors.Is(err, errInterrupt) {
+			source = ""
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if source == "" && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if ok, exit := s.command(line); !ok {
+				if exit != nil {
+					return exit
+				}
+				return nil
+			}
+			continue
+		}
+
+		source += line + "\n"
+		// an empty line ends a continuation early so the errors in the incomplete input get reported
+		if line != "" && !s.Complete(source) {
+			continue
+		}
+		if _, _, exit := s.RunInput(source); exit != nil {
+			return exit
+		}
+		source = ""
+	}
+}
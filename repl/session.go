@@ -0,0 +1,125 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+
+	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
+	e "github.com/constwhite/golox-interpreter/errorHandler"
+	"github.com/constwhite/golox-interpreter/interpreter"
+	"github.com/constwhite/golox-interpreter/parser"
+	"github.com/constwhite/golox-interpreter/resolver"
+	"github.com/constwhite/golox-interpreter/scanner"
+	t "github.com/constwhite/golox-interpreter/token"
+)
+
+// Session keeps a single interpreter and resolver alive between calls to Run so globals, resolved locals,
+// functions and classes declared by one input are still visible to the next
+type Session struct {
+	stdOut      io.Writer
+	stdErr      io.Writer
+	interpreter *interpreter.Interpreter
+	resolver    *resolver.Resolver
+	//Renderer writes out the diagnostics from each input, text written to stdErr by default
+	Renderer e.Renderer
+}
+
+func NewSession(stdOut io.Writer, stdErr io.Writer) *Session {
+	session := &Session{stdOut: stdOut, stdErr: stdErr, Renderer: e.NewTextRenderer(stdErr)}
+	session.Reset()
+	return session
+}
+
+// Reset throws away the session state and starts again with a fresh interpreter and resolver
+func (s *Session) Reset() {
+	s.interpreter = interpreter.NewInterpreter(s.stdOut, interpreter.AllCapabilities)
+	s.resolver = resolver.NewResolver(s.interpreter)
+}
+
+// Run scans, parses, resolves and interprets source against the session state.
+// hadError reports a scan, parse or resolve error, hadRuntimeError reports an error raised while interpreting and
+// exit is set when the program called exit
+func (s *Session) Run(source string) (hadError bool, hadRuntimeError bool, exit *interpreter.Exit) {
+	tokens, diagnostics := scanner.NewScanner(source).ScanTokens()
+	statements, parseDiagnostics := parser.NewParser(tokens).Parse()
+	if s.report(append(diagnostics, parseDiagnostics...)) {
+		return true, false, nil
+	}
+
+	if s.report(s.resolver.Resolve(statements)) {
+		return true, false, nil
+	}
+
+	diagnostics, exit = s.interpreter.Interpret(statements)
+	return false, s.report(diagnostics), exit
+}
+
+// RunInput runs a piece of prompt input. input that is a bare expression, with no trailing ';', is evaluated and
+// its value printed, anything else is run as statements
+func (s *Session) RunInput(source string) (hadError bool, hadRuntimeError bool, exit *interpreter.Exit) {
+	tokens, diagnostics := scanner.NewScanner(source).ScanTokens()
+	if len(diagnostics) > 0 || len(tokens) == 1 {
+		return s.Run(source)
+	}
+	expr, diagnostics := parser.NewParser(tokens).ParseExpression()
+	if len(diagnostics) > 0 {
+		return s.Run(source)
+	}
+
+	stmt := &abs.ExpressionStmt{Expression: expr}
+	if s.report(s.resolver.Resolve([]abs.Stmt{stmt})) {
+		return true, false, nil
+	}
+	value, diagnostics, exit := s.interpreter.Evaluate(stmt)
+	if s.report(diagnostics) {
+		return false, true, nil
+	}
+	if exit != nil {
+		return false, false, exit
+	}
+	fmt.Fprintln(s.stdOut, value)
+	return false, false, nil
+}
+
+// Complete reports whether source can be run as it is. source is incomplete when it leaves a bracket or string
+// open, or when the parser runs out of tokens part way through a statement
+func (s *Session) Complete(source string) bool {
+	scanner := scanner.NewScanner(source)
+	tokens, _ := scanner.ScanTokens()
+	if scanner.UnexpectedEOF {
+		return false
+	}
+
+	depth := 0
+	for index := 0; index < len(tokens); index++ {
+		switch tokens[index].TokenType {
+		case t.TokenLeftParen, t.TokenLeftBrace, t.TokenLeftBracket:
+			depth++
+		case t.TokenRightParen, t.TokenRightBrace, t.TokenRightBracket:
+			depth--
+		}
+	}
+	if depth > 0 {
+		return false
+	}
+
+	if _, diagnostics := parser.NewParser(tokens).ParseExpression(); len(diagnostics) == 0 {
+		return true
+	}
+	parser := parser.NewParser(tokens)
+	parser.Parse()
+	return !parser.UnexpectedEOF
+}
+
+// Interpreter returns the interpreter that holds the session state
+func (s *Session) Interpreter() *interpreter.Interpreter {
+	return s.interpreter
+}
+
+// renders the diagnostics and reports whether any of them are errors
+func (s *Session) report(diagnostics []e.Diagnostic) bool {
+	if len(diagnostics) > 0 {
+		s.Renderer.Render(diagnostics)
+	}
+	return e.HasErrors(diagnostics)
+}
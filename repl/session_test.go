@@ -0,0 +1,170 @@
+package repl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/constwhite/golox-interpreter/interpreter"
+)
+
+// returns a pipe that reads back the input, as if it had been piped in
+func pipe(t *testing.T, input string) *os.File {
+	t.Helper()
+	stdIn, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stdIn.Close() })
+	go func() {
+		writer.WriteString(input)
+		writer.Close()
+	}()
+	return stdIn
+}
+
+// runs the prompt over the input until the input ends
+func prompt(t *testing.T, session *Session, input string) {
+	t.Helper()
+	if err := session.Prompt(pipe(t, input)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "script.lox")
+	if err := os.WriteFile(script, []byte("var loaded = 1;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		stdOut string
+		stdErr string
+	}{
+		{name: "echoes an expression", input: "1 + 2\n", stdOut: "> 3\n> "},
+		{name: "runs statements", input: "print \"hi\";\n", stdOut: "> hi\n> "},
+		{name: "keeps globals between lines", input: "var a = 1;\na + 1\n", stdOut: "> > 2\n> "},
+		{name: "continues an open brace", input: "fun f() {\nreturn 1;\n}\nf()\n", stdOut: "> ... ... > 1\n> "},
+		{name: "continues an open string", input: "print \"a\nb\";\n", stdOut: "> ... a\nb\n> "},
+		{name: "continues an unfinished statement", input: "print 1 +\n2;\n", stdOut: "> ... 3\n> "},
+		{name: "empty line ends a continuation", input: "print (1\n\nprint 2;\n", stdOut: "> ... > 2\n> ", stdErr: "expect ')' after expression"},
+		{name: "reports runtime errors", input: "nil + 1\n", stdOut: "> > ", stdErr: "operands must be two numbers or two strings"},
+		{name: "help", input: ":help\n", stdOut: "> " + helpText + "> "},
+		{name: "ast", input: ":ast 1 + 2 * 3\n", stdOut: "> (+ 1 (* 2 3))\n> "},
+		{name: "ast named arguements", input: ":ast f(1, b: 2)\n", stdOut: "> (call f 1 (b: 2))\n> "},
+		{name: "ast reports errors", input: ":ast 1 +\n", stdOut: "> > ", stdErr: "expect expression"},
+		{name: "tokens", input: ":tokens var x\n", stdOut: "> VAR var\nIDENTIFIER x\nEOF \n> "},
+		{name: "reset", input: "var a = 1;\n:reset\na\n", stdOut: "> > > > ", stdErr: "undefined variable 'a'"},
+		{name: "load", input: ":load " + script + "\nloaded\n", stdOut: "> > 1\n> "},
+		{name: "load without a file", input: ":load\n", stdOut: "> > ", stdErr: "usage: :load <file>"},
+		{name: "unknown command", input: ":nope\n", stdOut: "> > ", stdErr: "unknown command :nope"},
+		{name: "quit", input: ":quit\nprint 1;\n", stdOut: "> "},
+		{name: "command inside a continuation is source", input: "print\n:x;\n", stdOut: "> ... > ", stdErr: "Error at ':'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdOut, stdErr bytes.Buffer
+			prompt(t, NewSession(&stdOut, &stdErr), test.input)
+			if stdOut.String() != test.stdOut {
+				t.Errorf("stdout = %q, want %q", stdOut.String(), test.stdOut)
+			}
+			if test.stdErr == "" && stdErr.Len() > 0 {
+				t.Errorf("stderr = %q, want nothing", stdErr.String())
+			}
+			if !strings.Contains(stdErr.String(), test.stdErr) {
+				t.Errorf("stderr = %q, want it to contain %q", stdErr.String(), test.stdErr)
+			}
+		})
+	}
+}
+
+func TestPromptExit(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "script.lox")
+	if err := os.WriteFile(script, []byte("exit(4);\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		input  string
+		code   int
+		stdOut string
+	}{
+		{name: "statement", input: "print 1;\nexit(2);\nprint 3;\n", code: 2, stdOut: "> 1\n> "},
+		{name: "expression", input: "exit(3)\nprint 3;\n", code: 3, stdOut: "> "},
+		{name: "loaded file", input: ":load " + script + "\nprint 3;\n", code: 4, stdOut: "> "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdOut bytes.Buffer
+			err := NewSession(&stdOut, &bytes.Buffer{}).Prompt(pipe(t, test.input))
+			var exit *interpreter.Exit
+			if !errors.As(err, &exit) || exit.Code != test.code {
+				t.Fatalf("Prompt returned %v, want exit status %v", err, test.code)
+			}
+			if stdOut.String() != test.stdOut {
+				t.Errorf("stdout = %q, want %q", stdOut.String(), test.stdOut)
+			}
+		})
+	}
+}
+
+// a compile error part way through resolving one input must not change how the next input resolves
+func TestRunAfterCompileError(t *testing.T) {
+	var stdErr bytes.Buffer
+	session := NewSession(&bytes.Buffer{}, &stdErr)
+	if hadError, _, _ := session.Run("class C < C {}"); !hadError {
+		t.Fatal("class C < C {} ran, want a compile error")
+	}
+	stdErr.Reset()
+	hadError, hadRuntimeError, _ := session.Run("print this;")
+	if !hadError || hadRuntimeError {
+		t.Fatalf("print this; gave compile error %v and runtime error %v, want only a compile error", hadError, hadRuntimeError)
+	}
+	if !strings.Contains(stdErr.String(), "can't use 'this' outside of a class") {
+		t.Errorf("stderr = %q, want the 'this' outside of a class error", stdErr.String())
+	}
+}
+
+func TestEnv(t *testing.T) {
+	var stdOut, stdErr bytes.Buffer
+	session := NewSession(&stdOut, &stdErr)
+	session.interpreter.Globals.Values = map[string]interface{}{}
+	prompt(t, session, "var b = [1];\nvar a = \"x\";\nfun c() {}\n:env\n")
+	want := "> > > > a = x\nb = [1]\nc = <fn c>\n> "
+	if stdOut.String() != want {
+		t.Errorf("stdout = %q, want %q", stdOut.String(), want)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		source   string
+		complete bool
+	}{
+		{"1 + 2", true},
+		{"print 1;", true},
+		{"print 1", false},
+		{"1 +", false},
+		{"fun f() {", false},
+		{"fun f() {}", true},
+		{"f(1,", false},
+		{"[1, 2", false},
+		{"{\"a\": 1", false},
+		{"\"open string", false},
+		{"// a comment", true},
+		{"", true},
+		{"print );", true},
+		{"}", true},
+	}
+	session := NewSession(&bytes.Buffer{}, &bytes.Buffer{})
+	for _, test := range tests {
+		if complete := session.Complete(test.source); complete != test.complete {
+			t.Errorf("Complete(%q) = %v, want %v", test.source, complete, test.complete)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package resolver
 
 import (
-	"io"
-
 	"fmt"
 
 	abs "github.com/constwhite/golox-interpreter/abstractSyntaxTree"
-	"github.com/constwhite/golox-interpreter/errorHandler"
+	e "github.com/constwhite/golox-interpreter/errorHandler"
 
 	in "github.com/constwhite/golox-interpreter/interpreter"
 	t "github.com/constwhite/golox-interpreter/token"
@@ -14,12 +12,14 @@ import (
 
 type Resolver struct {
 	interpreter    *in.Interpreter
-	stdErr         io.Writer
-	HadError       bool
+	diagnostics    []e.Diagnostic
 	scopes         scopes
 	currentFuntion functionType
 	currentClass   classType
-	ResolverError
+	//how many loops enclose the code being resolved within the current function
+	loopDepth int
+	//the labels of the enclosing loops within the current function
+	labels []string
 }
 
 type functionType uint8
@@ -38,29 +38,35 @@ const (
 	classTypeSubclass
 )
 
-type ResolverError struct {
-	error
-	Line int
-}
-
-func (re *ResolverError) Error() error {
-	return re.error
-}
 func NewResolver(interpreter *in.Interpreter) *Resolver {
 	return &Resolver{interpreter: interpreter}
 }
 
+// resolves the variables used in a list of top level statements, returning any errors found. the resolver can be
+// used again for more statements, as the REPL does for each input
+func (r *Resolver) Resolve(statements []abs.Stmt) []e.Diagnostic {
+	r.diagnostics = nil
+	//an error in an earlier input can stop resolving part way through, so start again from the top level
+	r.scopes = nil
+	r.currentFuntion = funcTypeNone
+	r.currentClass = classTypeNone
+	r.loopDepth = 0
+	r.labels = nil
+	r.resolveStatements(statements)
+	return r.diagnostics
+}
+
 //visit statements
 
-func (r *Resolver) VisitBlockStmt(stmt abs.BlockStmt) interface{} {
+func (r *Resolver) VisitBlockStmt(stmt *abs.BlockStmt) interface{} {
 
 	r.beginScope()
-	r.ResolveStatements(stmt.Statements)
+	r.resolveStatements(stmt.Statements)
 	r.endScope()
 	return nil
 }
 
-func (r *Resolver) VisitVarStmt(stmt abs.VarStmt) interface{} {
+func (r *Resolver) VisitVarStmt(stmt *abs.VarStmt) interface{} {
 	r.scopes.declare(stmt.Name)
 	if stmt.Initialiser != nil {
 		r.resolveExpr(stmt.Initialiser)
@@ -69,18 +75,18 @@ func (r *Resolver) VisitVarStmt(stmt abs.VarStmt) interface{} {
 	return nil
 }
 
-func (r *Resolver) VisitFunctionStmt(stmt abs.FunctionStmt) interface{} {
+func (r *Resolver) VisitFunctionStmt(stmt *abs.FunctionStmt) interface{} {
 	r.scopes.declare(stmt.Name)
 	r.scopes.define(stmt.Name)
 	r.resolveFunction(stmt, funcTypeFunction)
 	return nil
 }
 
-func (r *Resolver) VisitExpressionStmt(stmt abs.ExpressionStmt) interface{} {
+func (r *Resolver) VisitExpressionStmt(stmt *abs.ExpressionStmt) interface{} {
 	r.resolveExpr(stmt.Expression)
 	return nil
 }
-func (r *Resolver) VisitIfStmt(stmt abs.IfStmt) interface{} {
+func (r *Resolver) VisitIfStmt(stmt *abs.IfStmt) interface{} {
 	r.resolveExpr(stmt.Condition)
 	r.resolveStmt(stmt.ThenBranch)
 	if stmt.ElseBranch != nil {
@@ -88,35 +94,82 @@ func (r *Resolver) VisitIfStmt(stmt abs.IfStmt) interface{} {
 	}
 	return nil
 }
-func (r *Resolver) VisitPrintStmt(stmt abs.PrintStmt) interface{} {
+func (r *Resolver) VisitPrintStmt(stmt *abs.PrintStmt) interface{} {
 	r.resolveExpr(stmt.Expression)
 	return nil
 }
-func (r *Resolver) VisitReturnStmt(stmt abs.ReturnStmt) interface{} {
+func (r *Resolver) VisitReturnStmt(stmt *abs.ReturnStmt) interface{} {
 	if r.currentFuntion == funcTypeNone {
-		r.error(stmt.Keyword, "can not return from the top level code")
+		r.error(stmt.Keyword, e.CodeTopLevelReturn, "can not return from the top level code")
 	}
 	if stmt.Value != nil {
 		if r.currentFuntion == funcTypeInitialiser {
-			r.error(stmt.Keyword, "can not return from an initialiser")
+			r.error(stmt.Keyword, e.CodeInitialiserReturn, "can not return from an initialiser")
 		}
 		r.resolveExpr(stmt.Value)
 	}
 	return nil
 }
-func (r *Resolver) VisitWhileStmt(stmt abs.WhileStmt) interface{} {
+func (r *Resolver) VisitWhileStmt(stmt *abs.WhileStmt) interface{} {
 	r.resolveExpr(stmt.Condition)
+	if stmt.Label != nil {
+		if r.hasLabel(stmt.Label.Lexeme) {
+			r.error(*stmt.Label, e.CodeDuplicateLabel, "label already used by an enclosing loop")
+		}
+		r.labels = append(r.labels, stmt.Label.Lexeme)
+		defer func() { r.labels = r.labels[:len(r.labels)-1] }()
+	}
+	r.loopDepth++
 	r.resolveStmt(stmt.Body)
+	r.loopDepth--
+	if stmt.Increment != nil {
+		r.resolveExpr(stmt.Increment)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitBreakStmt(stmt *abs.BreakStmt) interface{} {
+	if r.loopDepth == 0 {
+		r.error(stmt.Keyword, e.CodeBreakOutsideLoop, "can not use 'break' outside of a loop")
+	}
+	r.resolveLabel(stmt.Label)
+	return nil
+}
+
+func (r *Resolver) VisitContinueStmt(stmt *abs.ContinueStmt) interface{} {
+	if r.loopDepth == 0 {
+		r.error(stmt.Keyword, e.CodeContinueOutsideLoop, "can not use 'continue' outside of a loop")
+	}
+	r.resolveLabel(stmt.Label)
 	return nil
 }
 
-func (r *Resolver) VisitClassStmt(stmt abs.ClassStmt) interface{} {
+// checks the label of a break or continue names an enclosing loop
+func (r *Resolver) resolveLabel(label *t.Token) {
+	if label != nil && r.loopDepth > 0 && !r.hasLabel(label.Lexeme) {
+		r.error(*label, e.CodeUndefinedLabel, fmt.Sprintf("undefined loop label '%v'", label.Lexeme))
+	}
+}
+
+func (r *Resolver) hasLabel(name string) bool {
+	for index := 0; index < len(r.labels); index++ {
+		if r.labels[index] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resolver) VisitClassStmt(stmt *abs.ClassStmt) interface{} {
 	enclosingClass := r.currentClass
 	r.currentClass = classTypeClass
+	defer func() {
+		r.currentClass = enclosingClass
+	}()
 	r.scopes.declare(stmt.Name)
 	r.scopes.define(stmt.Name)
 	if stmt.Superclass != nil && stmt.Name.Lexeme == stmt.Superclass.Name.Lexeme {
-		r.error(stmt.Superclass.Name, "a class can not inherit from itself")
+		r.error(stmt.Superclass.Name, e.CodeSelfInheritance, "a class can not inherit from itself")
 		return nil
 	}
 	if stmt.Superclass != nil {
@@ -140,18 +193,17 @@ func (r *Resolver) VisitClassStmt(stmt abs.ClassStmt) interface{} {
 		r.resolveFunction(method, declaration)
 	}
 	r.endScope()
-	r.currentClass = enclosingClass
 	return nil
 }
 
 // visit expressions
-func (r *Resolver) VisitVariableExpr(expr abs.VariableExpr) interface{} {
+func (r *Resolver) VisitVariableExpr(expr *abs.VariableExpr) interface{} {
 
 	if len(r.scopes) > 0 {
 		scope := r.scopes.peek()
 		defined, declared := scope[expr.Name.Lexeme]
-		if r.scopes.empty() && declared && !defined {
-			r.error(expr.Name, "cant't read local variable in its own initialiser.")
+		if declared && !defined {
+			r.error(expr.Name, e.CodeOwnInitialiser, "can't read local variable in its own initialiser")
 		}
 
 	}
@@ -159,65 +211,97 @@ func (r *Resolver) VisitVariableExpr(expr abs.VariableExpr) interface{} {
 	return nil
 }
 
-func (r *Resolver) VisitAssignExpr(expr abs.AssignExpr) interface{} {
+func (r *Resolver) VisitAssignExpr(expr *abs.AssignExpr) interface{} {
 	r.resolveExpr(expr.Value)
 	r.resolveLocal(expr, expr.Name)
 	return nil
 }
-func (r *Resolver) VisitBinaryExpr(expr abs.BinaryExpr) interface{} {
+func (r *Resolver) VisitBinaryExpr(expr *abs.BinaryExpr) interface{} {
 	r.resolveExpr(expr.Left)
 	r.resolveExpr(expr.Right)
 
 	return nil
 }
-func (r *Resolver) VisitCallExpr(expr abs.CallExpr) interface{} {
+func (r *Resolver) VisitCallExpr(expr *abs.CallExpr) interface{} {
 	r.resolveExpr(expr.Callee)
 	for i := 0; i < len(expr.Arguements); i++ {
 		arg := expr.Arguements[i]
 		r.resolveExpr(arg)
 	}
+	for i := 0; i < len(expr.NamedArguements); i++ {
+		r.resolveExpr(expr.NamedArguements[i].Value)
+	}
 	return nil
 }
-func (r *Resolver) VisitGroupingExpr(expr abs.GroupingExpr) interface{} {
+func (r *Resolver) VisitGroupingExpr(expr *abs.GroupingExpr) interface{} {
 	r.resolveExpr(expr.Expression)
 	return nil
 }
-func (r *Resolver) VisitLiteralExpr(expr abs.LiteralExpr) interface{} {
+func (r *Resolver) VisitLiteralExpr(expr *abs.LiteralExpr) interface{} {
 	return nil
 }
-func (r *Resolver) VisitLogicalExpr(expr abs.LogicalExpr) interface{} {
+func (r *Resolver) VisitLogicalExpr(expr *abs.LogicalExpr) interface{} {
 	r.resolveExpr(expr.Left)
 	r.resolveExpr(expr.Right)
 	return nil
 }
-func (r *Resolver) VisitUnaryExpr(expr abs.UnaryExpr) interface{} {
+func (r *Resolver) VisitUnaryExpr(expr *abs.UnaryExpr) interface{} {
 
 	r.resolveExpr(expr.Right)
 	return nil
 }
-func (r *Resolver) VisitGetExpr(expr abs.GetExpr) interface{} {
+func (r *Resolver) VisitGetExpr(expr *abs.GetExpr) interface{} {
+	r.resolveExpr(expr.Object)
+	return nil
+}
+func (r *Resolver) VisitSetExpr(expr *abs.SetExpr) interface{} {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	return nil
+}
+func (r *Resolver) VisitListExpr(expr *abs.ListExpr) interface{} {
+	for i := 0; i < len(expr.Elements); i++ {
+		r.resolveExpr(expr.Elements[i])
+	}
+	return nil
+}
+func (r *Resolver) VisitMapExpr(expr *abs.MapExpr) interface{} {
+	for i := 0; i < len(expr.Keys); i++ {
+		r.resolveExpr(expr.Keys[i])
+		r.resolveExpr(expr.Values[i])
+	}
+	return nil
+}
+func (r *Resolver) VisitIndexExpr(expr *abs.IndexExpr) interface{} {
 	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
 	return nil
 }
-func (r *Resolver) VisitSetExpr(expr abs.SetExpr) interface{} {
+func (r *Resolver) VisitSetIndexExpr(expr *abs.SetIndexExpr) interface{} {
 	r.resolveExpr(expr.Value)
 	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
 	return nil
 }
 
-func (r *Resolver) VisitSuperExpr(expr abs.SuperExpr) interface{} {
+func (r *Resolver) VisitFunctionExpr(expr *abs.FunctionExpr) interface{} {
+	r.resolveFunction(expr.Declaration, funcTypeFunction)
+	return nil
+}
+
+func (r *Resolver) VisitSuperExpr(expr *abs.SuperExpr) interface{} {
 	if r.currentClass == classTypeNone {
-		r.error(expr.Keyword, "can't use 'super' outside of class")
+		r.error(expr.Keyword, e.CodeSuperOutsideClass, "can't use 'super' outside of class")
 	} else if r.currentClass != classTypeSubclass {
-		r.error(expr.Keyword, "can't use 'super' in a class with no superclass")
+		r.error(expr.Keyword, e.CodeSuperWithoutSuperclass, "can't use 'super' in a class with no superclass")
 	}
 	r.resolveLocal(expr, expr.Keyword)
 	return nil
 }
 
-func (r *Resolver) VisitThisExpr(expr abs.ThisExpr) interface{} {
+func (r *Resolver) VisitThisExpr(expr *abs.ThisExpr) interface{} {
 	if r.currentClass == classTypeNone {
-		r.error(expr.Keyword, "can't use 'this' outside of a class")
+		r.error(expr.Keyword, e.CodeThisOutsideClass, "can't use 'this' outside of a class")
 		return nil
 	}
 	r.resolveLocal(expr, expr.Keyword)
@@ -226,19 +310,34 @@ func (r *Resolver) VisitThisExpr(expr abs.ThisExpr) interface{} {
 
 //helpers
 
-func (r *Resolver) resolveFunction(function abs.FunctionStmt, fnType functionType) {
+func (r *Resolver) resolveFunction(function *abs.FunctionStmt, fnType functionType) {
 	enclosingFunction := r.currentFuntion
 	r.currentFuntion = fnType
+	//a loop around a function declaration can't be broken out of from inside the function
+	enclosingLoopDepth := r.loopDepth
+	enclosingLabels := r.labels
+	r.loopDepth = 0
+	r.labels = nil
 
 	r.beginScope()
 	for i := 0; i < len(function.Params); i++ {
 		param := function.Params[i]
 		r.scopes.declare(param)
+		//defaults are evaluated in the function's scope so they can use the parameters before them
+		if function.Defaults[i] != nil {
+			r.resolveExpr(function.Defaults[i])
+		}
 		r.scopes.define(param)
 	}
-	r.ResolveStatements(function.Body)
+	if function.Rest != nil {
+		r.scopes.declare(*function.Rest)
+		r.scopes.define(*function.Rest)
+	}
+	r.resolveStatements(function.Body)
 	r.endScope()
 	r.currentFuntion = enclosingFunction
+	r.loopDepth = enclosingLoopDepth
+	r.labels = enclosingLabels
 }
 
 func (r *Resolver) resolveLocal(expr abs.Expr, name t.Token) {
@@ -252,13 +351,12 @@ func (r *Resolver) resolveLocal(expr abs.Expr, name t.Token) {
 }
 
 // traverses list of statements and resolves the variables in each statement
-func (r *Resolver) ResolveStatements(statements []abs.Stmt) bool {
+func (r *Resolver) resolveStatements(statements []abs.Stmt) {
 
 	for i := 0; i < len(statements); i++ {
 		stmt := statements[i]
 		r.resolveStmt(stmt)
 	}
-	return r.HadError
 }
 
 // resolves a single statement
@@ -280,15 +378,6 @@ func (r *Resolver) endScope() {
 
 //errors
 
-func (r *Resolver) error(token t.Token, msg string) {
-	var where string
-	if token.TokenType == t.TokenEOF {
-		where = "at end"
-	} else {
-		where = fmt.Sprintf("at '%v'", token.Lexeme)
-	}
-	err := ResolverError{error: fmt.Errorf("%v %v", msg, where)}
-	errorHandler.CompileError(r.stdErr, err.Error(), token.Line)
-	r.ResolverError = err
-	r.HadError = true
+func (r *Resolver) error(token t.Token, code string, msg string) {
+	r.diagnostics = append(r.diagnostics, e.NewError(e.PhaseResolve, code, msg, token.Span))
 }
@@ -2,8 +2,8 @@ package scanner
 
 import (
 	"fmt"
-	"io"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/constwhite/golox-interpreter/errorHandler"
 	"github.com/constwhite/golox-interpreter/token"
@@ -11,46 +11,61 @@ import (
 
 type Scanner struct {
 	//source code stored on scanner struct as string
-	source  string
-	tokens  []token.Token
-	current int
-	start   int
-	line    int
-	stdErr  io.Writer
+	source string
+	//shared by the span of every token so diagnostics can show the line the token came from
+	sourceRef *string
+	tokens    []token.Token
+	current   int
+	start     int
+	line      int
+	//offset of the first byte of the current line, used to work out columns
+	lineStart int
+	//position of the first byte of the token being scanned
+	startPosition token.Position
+	diagnostics   []errorHandler.Diagnostic
+	//set when the source ended inside a string
+	UnexpectedEOF bool
 }
 
-func NewScanner(source string, stdErr io.Writer) *Scanner {
-	return &Scanner{source: source, stdErr: stdErr}
+func NewScanner(source string) *Scanner {
+	return &Scanner{source: source, sourceRef: &source, line: 1}
 }
 
 var keywords = map[string]token.TokenType{
-	"and":    token.TokenAnd,
-	"class":  token.TokenClass,
-	"else":   token.TokenElse,
-	"false":  token.TokenFalse,
-	"for":    token.TokenFor,
-	"fun":    token.TokenFun,
-	"if":     token.TokenIf,
-	"nil":    token.TokenNil,
-	"or":     token.TokenOr,
-	"print":  token.TokenPrint,
-	"return": token.TokenReturn,
-	"super":  token.TokenSuper,
-	"this":   token.TokenThis,
-	"true":   token.TokenTrue,
-	"var":    token.TokenVar,
-	"while":  token.TokenWhile,
+	"and":      token.TokenAnd,
+	"break":    token.TokenBreak,
+	"class":    token.TokenClass,
+	"continue": token.TokenContinue,
+	"else":     token.TokenElse,
+	"false":    token.TokenFalse,
+	"for":      token.TokenFor,
+	"fun":      token.TokenFun,
+	"if":       token.TokenIf,
+	"nil":      token.TokenNil,
+	"or":       token.TokenOr,
+	"print":    token.TokenPrint,
+	"return":   token.TokenReturn,
+	"super":    token.TokenSuper,
+	"this":     token.TokenThis,
+	"true":     token.TokenTrue,
+	"var":      token.TokenVar,
+	"while":    token.TokenWhile,
 }
 
-func (s *Scanner) ScanTokens() []token.Token {
+// scans the whole source. the tokens are returned along with any errors found, the scanner carries on past an
+// error so every problem in the source gets reported
+func (s *Scanner) ScanTokens() ([]token.Token, []errorHandler.Diagnostic) {
 	//loop through source until reaching the end then appends one End of file (EOF) token
 	for !s.isAtEnd() {
 		s.start = s.current
+		s.startPosition = s.position(s.current)
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, token.Token{TokenType: token.TokenEOF, Lexeme: "", Literal: nil, Line: s.line})
-	return s.tokens
+	s.start = s.current
+	s.startPosition = s.position(s.current)
+	s.tokens = append(s.tokens, token.Token{TokenType: token.TokenEOF, Lexeme: "", Literal: nil, Line: s.line, Span: s.span()})
+	return s.tokens, s.diagnostics
 }
 
 func (s *Scanner) scanToken() {
@@ -65,10 +80,21 @@ func (s *Scanner) scanToken() {
 		s.addToken(token.TokenLeftBrace)
 	case '}':
 		s.addToken(token.TokenRightBrace)
+	case '[':
+		s.addToken(token.TokenLeftBracket)
+	case ']':
+		s.addToken(token.TokenRightBracket)
 	case ',':
 		s.addToken(token.TokenComma)
+	case ':':
+		s.addToken(token.TokenColon)
 	case '.':
-		s.addToken(token.TokenDot)
+		if s.peek() == '.' && s.peekNext() == '.' {
+			s.current += 2
+			s.addToken(token.TokenEllipsis)
+		} else {
+			s.addToken(token.TokenDot)
+		}
 	case '-':
 		s.addToken(token.TokenMinus)
 	case '+':
@@ -89,6 +115,8 @@ func (s *Scanner) scanToken() {
 
 		if s.match('=') {
 			s.addToken(token.TokenEqualEqual)
+		} else if s.match('>') {
+			s.addToken(token.TokenArrow)
 		} else {
 			s.addToken(token.TokenEqual)
 		}
@@ -120,7 +148,7 @@ func (s *Scanner) scanToken() {
 	case '\t':
 	//line break
 	case '\n':
-		s.line++
+		s.newLine()
 	//strings
 	case '"':
 		s.string()
@@ -131,8 +159,7 @@ func (s *Scanner) scanToken() {
 		} else if s.isAlpha(c) {
 			s.identifier()
 		} else {
-			errorHandler.ReportError(s.stdErr, fmt.Sprintf("Unexpected Character: %v", string(c)), "", s.line)
-			// s.error(fmt.Sprintf("Unexpected Character: %v", string(c)))
+			s.error(errorHandler.CodeUnexpectedCharacter, fmt.Sprintf("Unexpected Character: %v", string(c)))
 		}
 	}
 }
@@ -147,16 +174,16 @@ func (s *Scanner) advance() rune {
 func (s *Scanner) string() {
 	for s.peek() != '"' && !s.isAtEnd() {
 		//iterates over bytes in source segment until it reaches a closing ' " ' or reaches the end of the lexeme.
-		if s.peek() == '\n' {
-			s.line++
-		}
 		s.advance()
+		if s.previous() == '\n' {
+			s.newLine()
+		}
 	}
 
 	if s.isAtEnd() {
 		//if no closing ' " ' return error
-		errorHandler.ReportError(s.stdErr, "Unterminated string", "at end", s.line)
-		// s.error("Unterminated string")
+		s.error(errorHandler.CodeUnterminatedString, "Unterminated string")
+		s.UnexpectedEOF = true
 		return
 	}
 
@@ -252,17 +279,37 @@ func (s *Scanner) addTokenWithLiteral(tokenType token.TokenType, literal interfa
 			TokenType: tokenType,
 			Lexeme:    text,
 			Literal:   literal,
-			Line:      s.line,
+			Line:      s.startPosition.Line,
+			Span:      s.span(),
 		})
 
 }
 
+func (s *Scanner) previous() rune {
+	return rune(s.source[s.current-1])
+}
+
+// moves on to the line that starts after the '\n' that has just been consumed
+func (s *Scanner) newLine() {
+	s.line++
+	s.lineStart = s.current
+}
+
+func (s *Scanner) position(offset int) token.Position {
+	column := utf8.RuneCountInString(s.source[s.lineStart:offset]) + 1
+	return token.Position{Offset: offset, Line: s.line, Column: column}
+}
+
+// the span of the token being scanned, from its first byte to the current byte
+func (s *Scanner) span() token.Span {
+	return token.Span{Source: s.sourceRef, Start: s.startPosition, End: s.position(s.current)}
+}
+
 func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source)
 
 }
 
-// func (s *Scanner) error(msg string) {
-// 	// _, _ = s.stdErr.Write([]byte(fmt.Sprintf("[line: %v] Error: %s\n", s.line, msg)))
-// 	fmt.Fprintf(s.stdErr, "[line: %v] Error: %s\n", s.line, msg)
-// }
+func (s *Scanner) error(code string, msg string) {
+	s.diagnostics = append(s.diagnostics, errorHandler.NewError(errorHandler.PhaseScan, code, msg, s.span()))
+}
@@ -10,7 +10,10 @@ const (
 	TokenRightParen
 	TokenLeftBrace
 	TokenRightBrace
+	TokenLeftBracket
+	TokenRightBracket
 	TokenComma
+	TokenColon
 	TokenDot
 	TokenMinus
 	TokenPlus
@@ -27,6 +30,8 @@ const (
 	TokenGreaterEqual
 	TokenLesser
 	TokenLesserEqual
+	TokenArrow
+	TokenEllipsis
 
 	//literals
 	TokenIdentifier
@@ -35,7 +40,9 @@ const (
 
 	//keywords
 	TokenAnd
+	TokenBreak
 	TokenClass
+	TokenContinue
 	TokenElse
 	TokenFalse
 	TokenFun
@@ -54,13 +61,89 @@ const (
 	TokenEOF
 )
 
+var tokenNames = [...]string{
+	TokenLeftParen:    "LEFT_PAREN",
+	TokenRightParen:   "RIGHT_PAREN",
+	TokenLeftBrace:    "LEFT_BRACE",
+	TokenRightBrace:   "RIGHT_BRACE",
+	TokenLeftBracket:  "LEFT_BRACKET",
+	TokenRightBracket: "RIGHT_BRACKET",
+	TokenComma:        "COMMA",
+	TokenColon:        "COLON",
+	TokenDot:          "DOT",
+	TokenMinus:        "MINUS",
+	TokenPlus:         "PLUS",
+	TokenSemiColon:    "SEMICOLON",
+	TokenSlash:        "SLASH",
+	TokenStar:         "STAR",
+	TokenBang:         "BANG",
+	TokenBangEqual:    "BANG_EQUAL",
+	TokenEqual:        "EQUAL",
+	TokenEqualEqual:   "EQUAL_EQUAL",
+	TokenGreater:      "GREATER",
+	TokenGreaterEqual: "GREATER_EQUAL",
+	TokenLesser:       "LESS",
+	TokenLesserEqual:  "LESS_EQUAL",
+	TokenArrow:        "ARROW",
+	TokenEllipsis:     "ELLIPSIS",
+	TokenIdentifier:   "IDENTIFIER",
+	TokenString:       "STRING",
+	TokenNumber:       "NUMBER",
+	TokenAnd:          "AND",
+	TokenBreak:        "BREAK",
+	TokenClass:        "CLASS",
+	TokenContinue:     "CONTINUE",
+	TokenElse:         "ELSE",
+	TokenFalse:        "FALSE",
+	TokenFun:          "FUN",
+	TokenFor:          "FOR",
+	TokenIf:           "IF",
+	TokenNil:          "NIL",
+	TokenOr:           "OR",
+	TokenPrint:        "PRINT",
+	TokenReturn:       "RETURN",
+	TokenSuper:        "SUPER",
+	TokenThis:         "THIS",
+	TokenTrue:         "TRUE",
+	TokenVar:          "VAR",
+	TokenWhile:        "WHILE",
+	TokenEOF:          "EOF",
+}
+
+func (tt TokenType) String() string {
+	if int(tt) < len(tokenNames) {
+		return tokenNames[tt]
+	}
+	return fmt.Sprintf("TokenType(%d)", uint8(tt))
+}
+
+// a point in the source. Offset is the byte offset from the start of the source, Line and Column count from 1
+// with Column counted in characters
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// the stretch of source from Start up to but not including End. Source points at the text the span was scanned
+// from so a diagnostic can show the line even after the REPL has moved on to new input
+type Span struct {
+	Source *string
+	Start  Position
+	End    Position
+}
+
 type Token struct {
 	TokenType TokenType
 	Lexeme    string
 	Literal   interface{}
 	Line      int
+	Span      Span
 }
 
-func (t Token) toString() string {
-	return fmt.Sprintf("%d %s %s", t.TokenType, t.Lexeme, t.Literal)
+func (t Token) String() string {
+	if t.Literal == nil {
+		return fmt.Sprintf("%v %s", t.TokenType, t.Lexeme)
+	}
+	return fmt.Sprintf("%v %s %v", t.TokenType, t.Lexeme, t.Literal)
 }
@@ -79,19 +79,19 @@ func defineVisitor(file *os.File, baseName string, exprTypes []string) {
 	for i := 0; i < len(exprTypes); i++ {
 		exprType := exprTypes[i]
 		exprName := strings.Split(exprType, ":")[0]
-		fmt.Fprintf(file, "Visit%v(%v %v) interface{} \n", exprName, baseName, exprName)
+		fmt.Fprintf(file, "Visit%v(%v *%v) interface{} \n", exprName, baseName, exprName)
 	}
 	fmt.Fprintf(file, "}\n")
 
 }
 
 /*
-func (b structName) Accept(visitor ExprVisitor) interface{} {
+func (b *structName) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitStructName(b)
 }
 */
 
 func defineAcceptMethod(file *os.File, structName string, baseName string) {
 	baseNameUpper := fmt.Sprintf("%v%v", strings.ToUpper(string(baseName[0])), string(baseName[1:]))
-	fmt.Fprintf(file, "func (%v %v) Accept(visitor %vVisitor) interface{}{\nreturn visitor.Visit%v(%v)}\n", string(baseName[0]), structName, baseNameUpper, structName, string(baseName[0]))
+	fmt.Fprintf(file, "func (%v *%v) Accept(visitor %vVisitor) interface{}{\nreturn visitor.Visit%v(%v)}\n", string(baseName[0]), structName, baseNameUpper, structName, string(baseName[0]))
 }